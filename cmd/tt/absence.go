@@ -0,0 +1,211 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/roccoblues/tt/pkg/ical"
+	"github.com/roccoblues/tt/pkg/timesheet"
+)
+
+// dateKey is the layout which identifies the day of an absence.
+const dateKey = "2006-01-02"
+
+// absence records, deletes or imports absences.
+func absence(sheet *timesheet.Sheet, args []string) ([]timesheet.Event, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("absence: expected add, delete, import or list")
+	}
+
+	switch args[0] {
+	case "add":
+		return addAbsence(sheet, args[1:])
+	case "delete":
+		return removeAbsences(sheet, args[1:])
+	case "import":
+		return importAbsences(sheet, args[1:])
+	default:
+		return nil, fmt.Errorf("absence: unknown command '%s'", args[0])
+	}
+}
+
+// addAbsence records an absence on every day of a date range.
+func addAbsence(sheet *timesheet.Sheet, args []string) ([]timesheet.Event, error) {
+	fs := flag.NewFlagSet("absence add", flag.ExitOnError)
+	half := fs.Bool("half", false, "only half of the day")
+	note := fs.String("note", "", "note about the absence")
+	fs.Parse(args)
+
+	if fs.NArg() != 2 {
+		return nil, fmt.Errorf("absence add: expected type and date or date range")
+	}
+	t := timesheet.AbsenceType(fs.Arg(0))
+	from, to, err := dateRangeArg(sheet, fs.Arg(1))
+	if err != nil {
+		return nil, err
+	}
+
+	var events []timesheet.Event
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		a := timesheet.Absence{Date: day, Type: t, Half: *half, Note: *note}
+		events = append(events, timesheet.Event{Type: timesheet.EventAddAbsence, Absence: a})
+	}
+
+	return events, nil
+}
+
+// removeAbsences deletes the absences of a date range, optionally only
+// those of the given type.
+func removeAbsences(sheet *timesheet.Sheet, args []string) ([]timesheet.Event, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return nil, fmt.Errorf("absence delete: expected date or date range and optional type")
+	}
+	from, to, err := dateRangeArg(sheet, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	var events []timesheet.Event
+	absences := sheet.Absences()
+	first, last := from.Format(dateKey), to.Format(dateKey)
+	for n := len(absences) - 1; n >= 0; n-- {
+		a := absences[n]
+		// absences are calendar dates, compare them without time zone
+		if day := a.Date.Format(dateKey); day < first || day > last || len(args) == 2 && string(a.Type) != args[1] {
+			continue
+		}
+		events = append(events, timesheet.Event{Type: timesheet.EventDeleteAbsence, Index: n})
+	}
+	if len(events) == 0 {
+		return nil, fmt.Errorf("absence delete: no absence on %s", args[0])
+	}
+
+	return events, nil
+}
+
+// importAbsences records the days of the events of an iCalendar file as
+// absences. Days which already have an absence of the type are skipped, so
+// importing the same file again doesn't change anything.
+func importAbsences(sheet *timesheet.Sheet, args []string) ([]timesheet.Event, error) {
+	fs := flag.NewFlagSet("absence import", flag.ExitOnError)
+	kind := fs.String("type", string(timesheet.AbsenceHoliday), "type of the imported absences")
+	category := fs.String("category", "", "only import events of the category")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return nil, fmt.Errorf("absence import: missing iCalendar file")
+	}
+	file, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	calendarEvents, err := ical.Parse(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", fs.Arg(0), err)
+	}
+
+	t := timesheet.AbsenceType(*kind)
+	recorded := map[string]bool{}
+	for _, a := range sheet.Absences() {
+		if a.Type == t {
+			recorded[a.Date.Format(dateKey)] = true
+		}
+	}
+
+	var events []timesheet.Event
+	for _, e := range calendarEvents {
+		if *category != "" && !e.HasCategory(*category) {
+			continue
+		}
+		for _, day := range eventDays(e, location(sheet)) {
+			if recorded[day.Format(dateKey)] {
+				continue
+			}
+			recorded[day.Format(dateKey)] = true
+			a := timesheet.Absence{Date: day, Type: t, Note: e.Summary}
+			events = append(events, timesheet.Event{Type: timesheet.EventAddAbsence, Absence: a})
+		}
+	}
+
+	return events, nil
+}
+
+// eventDays returns the days covered by a calendar event. The times of
+// events which aren't all-day are taken in loc.
+func eventDays(e ical.Event, loc *time.Location) []time.Time {
+	start, end := e.Start, e.End
+	if !e.AllDay {
+		start, end = start.In(loc), end.In(loc)
+	}
+	year, month, day := start.Date()
+	first := time.Date(year, month, day, 0, 0, 0, 0, start.Location())
+
+	days := []time.Time{first}
+	for d := first.AddDate(0, 0, 1); d.Before(end); d = d.AddDate(0, 0, 1) {
+		days = append(days, d)
+	}
+	return days
+}
+
+// listAbsences writes the absences of a year and the vacation days left
+// of the yearly allowance.
+func listAbsences(sheet *timesheet.Sheet, args []string, targets timesheet.Targets, allowance float64, w io.Writer) error {
+	fs := flag.NewFlagSet("absence list", flag.ExitOnError)
+	year := fs.Int("year", now(sheet).Year(), "list absences of the year")
+	fs.Parse(args)
+
+	for _, a := range sheet.Absences() {
+		if a.Date.Year() != *year {
+			continue
+		}
+		kind := string(a.Type)
+		if a.Half {
+			kind = "half " + kind
+		}
+		fmt.Fprintf(w, "%s  %s", a.Date.Format(sheet.DateFormat), kind)
+		if a.Note != "" {
+			fmt.Fprintf(w, "  %s", a.Note)
+		}
+		fmt.Fprintln(w, "")
+	}
+
+	taken := sheet.VacationDays(*year, targets)
+	fmt.Fprintf(w, "\nVacation %d: %g days taken", *year, taken)
+	if allowance > 0 {
+		fmt.Fprintf(w, ", %g of %g remaining", allowance-taken, allowance)
+	}
+	fmt.Fprintln(w, "")
+
+	return nil
+}
+
+// dateRangeArg parses a date or a date range like "2026-12-22..2026-12-31".
+// Both dates are included.
+func dateRangeArg(sheet *timesheet.Sheet, value string) (time.Time, time.Time, error) {
+	p := parser(sheet)
+
+	parts := strings.SplitN(value, "..", 2)
+	from, err := p.Date(parts[0])
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if len(parts) == 1 {
+		return from, from, nil
+	}
+
+	to, err := p.Date(parts[1])
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if to.Before(from) {
+		return time.Time{}, time.Time{}, fmt.Errorf("end date %s is earlier as start date %s", parts[1], parts[0])
+	}
+
+	return from, to, nil
+}
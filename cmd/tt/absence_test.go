@@ -0,0 +1,145 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/roccoblues/tt/pkg/ical"
+	"github.com/roccoblues/tt/pkg/timesheet"
+)
+
+func TestAddAbsence(t *testing.T) {
+	sheet := timesheet.NewSheet("02.01.2006", "15:04", nil)
+
+	events, err := addAbsence(sheet, []string{"-half", "vacation", "2026-12-30..31.12.2026"})
+	if err != nil {
+		t.Fatalf("addAbsence() error = %v", err)
+	}
+	want := []timesheet.Event{
+		{Type: timesheet.EventAddAbsence, Absence: timesheet.Absence{Date: date(2026, time.December, 30), Type: timesheet.AbsenceVacation, Half: true}},
+		{Type: timesheet.EventAddAbsence, Absence: timesheet.Absence{Date: date(2026, time.December, 31), Type: timesheet.AbsenceVacation, Half: true}},
+	}
+	if diff := cmp.Diff(want, events); diff != "" {
+		t.Errorf("addAbsence() differs: (-want +got)\n%s", diff)
+	}
+
+	for _, args := range [][]string{{"vacation"}, {"vacation", "2026-12-31..2026-12-30"}, {"vacation", "2026-12-32"}} {
+		if _, err := addAbsence(sheet, args); err == nil {
+			t.Errorf("addAbsence(%v) succeeded", args)
+		}
+	}
+}
+
+func TestRemoveAbsences(t *testing.T) {
+	sheet := timesheet.NewSheet("02.01.2006", "15:04", nil)
+	for _, a := range []timesheet.Absence{
+		{Date: date(2026, time.December, 24), Type: timesheet.AbsenceVacation},
+		{Date: date(2026, time.December, 25), Type: timesheet.AbsenceVacation},
+		{Date: date(2026, time.December, 25), Type: timesheet.AbsenceHoliday},
+		{Date: date(2026, time.December, 28), Type: timesheet.AbsenceVacation},
+	} {
+		if err := sheet.AddAbsence(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	events, err := removeAbsences(sheet, []string{"2026-12-24..2026-12-27", "vacation"})
+	if err != nil {
+		t.Fatalf("removeAbsences() error = %v", err)
+	}
+	want := []timesheet.Event{
+		{Type: timesheet.EventDeleteAbsence, Index: 1},
+		{Type: timesheet.EventDeleteAbsence, Index: 0},
+	}
+	if diff := cmp.Diff(want, events); diff != "" {
+		t.Errorf("removeAbsences() differs: (-want +got)\n%s", diff)
+	}
+
+	if _, err := removeAbsences(sheet, []string{"2026-12-27"}); err == nil {
+		t.Errorf("removeAbsences() of day without absence succeeded")
+	}
+}
+
+func TestImportAbsences(t *testing.T) {
+	sheet := timesheet.NewSheet("02.01.2006", "15:04", nil)
+	if err := sheet.AddAbsence(timesheet.Absence{Date: date(2026, time.December, 26), Type: timesheet.AbsenceHoliday}); err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := importAbsences(sheet, []string{"-category", "holidays", "testdata/holidays.ics"})
+	if err != nil {
+		t.Fatalf("importAbsences() error = %v", err)
+	}
+	want := []timesheet.Event{
+		{Type: timesheet.EventAddAbsence, Absence: timesheet.Absence{Date: date(2026, time.December, 25), Type: timesheet.AbsenceHoliday, Note: "Christmas"}},
+	}
+	if diff := cmp.Diff(want, events); diff != "" {
+		t.Errorf("importAbsences() differs: (-want +got)\n%s", diff)
+	}
+	for _, e := range events {
+		if err := sheet.Apply(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// importing again doesn't add anything
+	events, err = importAbsences(sheet, []string{"-category", "holidays", "testdata/holidays.ics"})
+	if err != nil {
+		t.Fatalf("importAbsences() error = %v", err)
+	}
+	if len(events) != 0 {
+		t.Errorf("importAbsences() again returned %d events", len(events))
+	}
+
+	events, err = importAbsences(sheet, []string{"-type", "comp-time", "testdata/holidays.ics"})
+	if err != nil {
+		t.Fatalf("importAbsences() error = %v", err)
+	}
+	if len(events) != 3 {
+		t.Errorf("importAbsences() of all events returned %d events, want 3", len(events))
+	}
+}
+
+func TestEventDays(t *testing.T) {
+	newYork := time.FixedZone("EDT", -4*60*60)
+	tests := []struct {
+		name  string
+		event ical.Event
+		want  []string
+	}{
+		{
+			name: "all-day",
+			event: ical.Event{
+				Start:  time.Date(2026, time.December, 24, 0, 0, 0, 0, time.UTC),
+				End:    time.Date(2026, time.December, 27, 0, 0, 0, 0, time.UTC),
+				AllDay: true,
+			},
+			want: []string{"2026-12-24", "2026-12-25", "2026-12-26"},
+		},
+		{
+			name: "in time zone",
+			event: ical.Event{
+				Start: time.Date(2026, time.December, 25, 2, 0, 0, 0, time.UTC),
+				End:   time.Date(2026, time.December, 25, 6, 0, 0, 0, time.UTC),
+			},
+			want: []string{"2026-12-24", "2026-12-25"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, d := range eventDays(tt.event, newYork) {
+				got = append(got, d.Format(dateKey))
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("eventDays() differs: (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.Now().Location())
+}
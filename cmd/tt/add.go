@@ -0,0 +1,44 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/roccoblues/tt/pkg/timesheet"
+)
+
+// add inserts complete intervals on any day.
+func add(sheet *timesheet.Sheet, args []string) ([]timesheet.Event, error) {
+	var tags tagsFlag
+	fs := flag.NewFlagSet("add", flag.ExitOnError)
+	project := fs.String("project", "", "record intervals for project")
+	fs.Var(&tags, "tag", "tag intervals (repeatable)")
+	note := fs.String("note", "", "note what you worked on")
+	fs.Parse(args)
+
+	if fs.NArg() < 2 {
+		return nil, fmt.Errorf("add: expected date and at least one interval")
+	}
+	p := parser(sheet)
+	date, err := p.Date(fs.Arg(0))
+	if err != nil {
+		return nil, err
+	}
+
+	// the end time may include the date for intervals ending on a later day
+	parseTime := func(t string) (time.Time, error) { return p.TimeOn(date, t) }
+	var events []timesheet.Event
+	for _, value := range fs.Args()[1:] {
+		interval, err := timesheet.ParseInterval(value, parseTime, parseTime)
+		if err != nil {
+			return nil, fmt.Errorf("add %s: %s", value, err)
+		}
+		interval.Project = *project
+		interval.Tags = tags
+		interval.Note = *note
+		events = append(events, timesheet.Event{Type: timesheet.EventAdd, Interval: interval})
+	}
+
+	return events, nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/roccoblues/tt/pkg/timesheet"
+)
+
+func TestAdd(t *testing.T) {
+	loc := time.Now().Location()
+	day := time.Date(2018, time.September, 1, 0, 0, 0, 0, loc)
+
+	tests := []struct {
+		name       string
+		value      string
+		timeFormat string
+		want       timesheet.Interval
+		wantErr    bool
+	}{
+		{
+			name:       "interval",
+			value:      "09:00-12:30",
+			timeFormat: "15:04",
+			want: timesheet.Interval{
+				Start: time.Date(2018, time.September, 1, 9, 0, 0, 0, loc),
+				End:   time.Date(2018, time.September, 1, 12, 30, 0, 0, loc),
+			},
+		},
+		{
+			name:       "open",
+			value:      "13:15-",
+			timeFormat: "15:04",
+			want:       timesheet.Interval{Start: time.Date(2018, time.September, 1, 13, 15, 0, 0, loc)},
+		},
+		{
+			name:       "end on next day",
+			value:      "22:00-02.09.2018 02:00",
+			timeFormat: "15:04",
+			want: timesheet.Interval{
+				Start: time.Date(2018, time.September, 1, 22, 0, 0, 0, loc),
+				End:   time.Date(2018, time.September, 2, 2, 0, 0, 0, loc),
+			},
+		},
+		{
+			name:       "dash in time format",
+			value:      "09-00-12-30",
+			timeFormat: "15-04",
+			want: timesheet.Interval{
+				Start: time.Date(2018, time.September, 1, 9, 0, 0, 0, loc),
+				End:   time.Date(2018, time.September, 1, 12, 30, 0, 0, loc),
+			},
+		},
+		{
+			name:       "invalid",
+			value:      "09:00",
+			timeFormat: "15:04",
+			wantErr:    true,
+		},
+		{
+			name:       "end before start",
+			value:      "12:30-09:00",
+			timeFormat: "15:04",
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sheet := timesheet.NewSheet("02.01.2006", tt.timeFormat, nil)
+			got, err := add(sheet, []string{"-note", "review", day.Format("02.01.2006"), tt.value})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("add() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			tt.want.Note = "review"
+			want := []timesheet.Event{{Type: timesheet.EventAdd, Interval: tt.want}}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("add() differs: (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
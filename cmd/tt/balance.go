@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/roccoblues/tt/pkg/timesheet"
+)
+
+// balance writes the overtime from the date since up to today. The opening
+// balance is carried over from before since. Without since the balance
+// starts with the first interval.
+func balance(sheet *timesheet.Sheet, args []string, opts timesheet.PrintOptions, since string, opening time.Duration, w io.Writer) error {
+	if opts.Targets == nil {
+		return fmt.Errorf("balance: no targets, set them with -targets")
+	}
+	if len(args) > 1 {
+		return fmt.Errorf("balance: unexpected argument '%s'", args[1])
+	}
+	if len(args) == 1 {
+		since = args[0]
+	}
+
+	p := parser(sheet)
+	today, err := p.Date("today")
+	if err != nil {
+		return err
+	}
+
+	from := today
+	if since != "" {
+		if from, err = p.Date(since); err != nil {
+			return err
+		}
+	} else if intervals := sheet.Intervals(); len(intervals) > 0 {
+		year, month, day := intervals[0].Start.In(today.Location()).Date()
+		from = time.Date(year, month, day, 0, 0, 0, 0, today.Location())
+	}
+
+	b := sheet.Balance(from, today.AddDate(0, 0, 1), opts.Targets, opts.RoundTo)
+
+	fmt.Fprintf(w, "Since %s\n\n", from.Format(sheet.DateFormat))
+	fmt.Fprintf(w, "Worked:  %.2f\n", b.Worked.Hours())
+	fmt.Fprintf(w, "Target:  %.2f\n", b.Target.Hours())
+	if b.Credit != 0 {
+		fmt.Fprintf(w, "Absent:  %.2f\n", b.Credit.Hours())
+	}
+	if opening != 0 {
+		fmt.Fprintf(w, "Opening: %+.2f\n", opening.Hours())
+	}
+	fmt.Fprintf(w, "Balance: %+.2f\n", (opening + b.Overtime()).Hours())
+
+	return nil
+}
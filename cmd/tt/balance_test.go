@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/roccoblues/tt/pkg/timesheet"
+)
+
+func TestBalance(t *testing.T) {
+	year, month, day := time.Now().AddDate(0, 0, -1).Date()
+	yesterday := time.Date(year, month, day, 0, 0, 0, 0, time.Now().Location())
+
+	sheet := timesheet.NewSheet("02.01.2006", "15:04", []timesheet.Interval{
+		{Start: yesterday.Add(-14 * time.Hour), End: yesterday.Add(-12 * time.Hour)},
+		{Start: yesterday.Add(9 * time.Hour), End: yesterday.Add(17 * time.Hour)},
+	})
+	targets, err := timesheet.ParseTargets("mon-sun=8h")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := timesheet.PrintOptions{RoundTo: 15 * time.Minute, Targets: targets}
+
+	var got bytes.Buffer
+	if err := balance(sheet, nil, opts, "yesterday", 2*time.Hour, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := "Since " + yesterday.Format("02.01.2006") + "\n\n" +
+		"Worked:  8.00\n" +
+		"Target:  16.00\n" +
+		"Opening: +2.00\n" +
+		"Balance: -6.00\n"
+	if diff := cmp.Diff(want, got.String()); diff != "" {
+		t.Errorf("balance() differs: (-want +got)\n%s", diff)
+	}
+
+	got.Reset()
+	if err := balance(sheet, nil, opts, "", 0, &got); err != nil {
+		t.Fatal(err)
+	}
+	want = "Since " + yesterday.AddDate(0, 0, -1).Format("02.01.2006") + "\n\n" +
+		"Worked:  10.00\n" +
+		"Target:  24.00\n" +
+		"Balance: -14.00\n"
+	if diff := cmp.Diff(want, got.String()); diff != "" {
+		t.Errorf("balance() without since differs: (-want +got)\n%s", diff)
+	}
+
+	if err := balance(sheet, nil, timesheet.PrintOptions{}, "", 0, &got); err == nil {
+		t.Errorf("balance() without targets succeeded")
+	}
+}
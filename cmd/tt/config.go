@@ -0,0 +1,194 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// config is the content of the config file. Settings are named like the
+// global flags, profiles contain settings which are only used if the
+// profile is selected.
+type config struct {
+	settings map[string]json.RawMessage
+	profiles map[string]map[string]json.RawMessage
+}
+
+// configPath returns the path of the config file.
+func configPath(getenv func(string) string, home string) string {
+	if path := getenv("TT_CONFIG"); path != "" {
+		return path
+	}
+	if dir := getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "tt", "config.json")
+	}
+	return filepath.Join(home, ".config", "tt", "config.json")
+}
+
+// readConfig reads the config file. A missing file is an empty config.
+func readConfig(path string) (config, error) {
+	cfg := config{
+		settings: map[string]json.RawMessage{},
+		profiles: map[string]map[string]json.RawMessage{},
+	}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+	defer file.Close()
+
+	if err := json.NewDecoder(file).Decode(&cfg.settings); err != nil && err != io.EOF {
+		return cfg, fmt.Errorf("%s: %s", path, err)
+	}
+	if profiles, ok := cfg.settings["profiles"]; ok {
+		if err := json.Unmarshal(profiles, &cfg.profiles); err != nil {
+			return cfg, fmt.Errorf("%s: profiles: %s", path, err)
+		}
+		delete(cfg.settings, "profiles")
+	}
+
+	return cfg, nil
+}
+
+// envName returns the environment variable overriding the flag.
+func envName(flagName string) string {
+	return "TT_" + strings.ToUpper(strings.Replace(flagName, "-", "_", -1))
+}
+
+// configure sets the flags which are not given on the command line from
+// the environment, the selected profile or the config file, in this order.
+// Repeatable flags take a comma separated list from the environment. It
+// returns where the value of each flag came from.
+func configure(fs *flag.FlagSet, path string, getenv func(string) string) (map[string]string, error) {
+	cfg, err := readConfig(path)
+	if err != nil {
+		return nil, err
+	}
+
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	for name := range cfg.settings {
+		if fs.Lookup(name) == nil {
+			return nil, fmt.Errorf("%s: unknown setting '%s'", path, name)
+		}
+	}
+	for profile, settings := range cfg.profiles {
+		for name := range settings {
+			if fs.Lookup(name) == nil || name == "profile" {
+				return nil, fmt.Errorf("%s: unknown setting '%s' in profile '%s'", path, name, profile)
+			}
+		}
+	}
+
+	sources := map[string]string{}
+	apply := func(f *flag.Flag, profile map[string]json.RawMessage, profileName string) error {
+		switch {
+		case set[f.Name]:
+			sources[f.Name] = "flag"
+		case getenv(envName(f.Name)) != "":
+			sources[f.Name] = "env " + envName(f.Name)
+			values := []string{getenv(envName(f.Name))}
+			if _, ok := f.Value.(*tagsFlag); ok {
+				values = strings.Split(values[0], ",")
+			}
+			for _, v := range values {
+				if err := fs.Set(f.Name, v); err != nil {
+					return fmt.Errorf("%s: %s", envName(f.Name), err)
+				}
+			}
+		case profile[f.Name] != nil:
+			sources[f.Name] = fmt.Sprintf("profile %s in %s", profileName, path)
+			return setRaw(fs, f.Name, profile[f.Name], path)
+		case cfg.settings[f.Name] != nil:
+			sources[f.Name] = path
+			return setRaw(fs, f.Name, cfg.settings[f.Name], path)
+		default:
+			sources[f.Name] = "default"
+		}
+		return nil
+	}
+
+	// the profile decides where the other settings come from
+	var profile map[string]json.RawMessage
+	var profileName string
+	if f := fs.Lookup("profile"); f != nil {
+		if err := apply(f, nil, ""); err != nil {
+			return nil, err
+		}
+		if profileName = f.Value.String(); profileName != "" {
+			var ok bool
+			if profile, ok = cfg.profiles[profileName]; !ok {
+				return nil, fmt.Errorf("unknown profile '%s'", profileName)
+			}
+		}
+	}
+
+	var visitErr error
+	fs.VisitAll(func(f *flag.Flag) {
+		if f.Name != "profile" && visitErr == nil {
+			visitErr = apply(f, profile, profileName)
+		}
+	})
+
+	return sources, visitErr
+}
+
+// setRaw sets the flag to a JSON value of the config file. Arrays set a
+// repeatable flag multiple times.
+func setRaw(fs *flag.FlagSet, name string, raw json.RawMessage, path string) error {
+	var values []interface{}
+	if err := json.Unmarshal(raw, &values); err != nil {
+		var value interface{}
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return fmt.Errorf("%s: %s: %s", path, name, err)
+		}
+		values = []interface{}{value}
+	}
+
+	for _, v := range values {
+		s, ok := v.(string)
+		if !ok {
+			s = fmt.Sprint(v)
+		}
+		if err := fs.Set(name, s); err != nil {
+			return fmt.Errorf("%s: %s: %s", path, name, err)
+		}
+	}
+
+	return nil
+}
+
+// expandHome replaces a leading "~" with the home directory.
+func expandHome(path, home string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		return filepath.Join(home, path[1:])
+	}
+	return path
+}
+
+// printConfig writes the effective value and its source of every flag.
+func printConfig(fs *flag.FlagSet, path string, sources map[string]string, w io.Writer) {
+	fmt.Fprintf(w, "config file: %s\n\n", path)
+
+	var names []string
+	fs.VisitAll(func(f *flag.Flag) { names = append(names, f.Name) })
+	sort.Strings(names)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SETTING\tVALUE\tSOURCE")
+	for _, name := range names {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", name, fs.Lookup(name).Value, sources[name])
+	}
+	tw.Flush()
+}
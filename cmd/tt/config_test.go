@@ -0,0 +1,140 @@
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestConfigure(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "config.json")
+	config := `{
+  "file": "~/work.json",
+  "round-to": 6,
+  "no-split": true,
+  "date-format": "2006-01-02",
+  "profiles": {
+    "acme": {"file": "~/acme.json", "tag": ["billable", "remote"]}
+  }
+}`
+	if err := ioutil.WriteFile(path, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		args        []string
+		env         map[string]string
+		want        map[string]string
+		wantSources map[string]string
+		wantErr     bool
+	}{
+		{
+			name: "config file",
+			want: map[string]string{"file": "~/work.json", "round-to": "6", "no-split": "true", "lock-timeout": "5s", "tag": ""},
+			wantSources: map[string]string{
+				"file": path, "round-to": path, "no-split": path, "lock-timeout": "default", "tag": "default", "profile": "default",
+			},
+		},
+		{
+			name: "environment and flags",
+			args: []string{"-round-to", "30"},
+			env:  map[string]string{"TT_ROUND_TO": "1", "TT_FILE": "/tmp/tt.json", "TT_DATE_FORMAT": "Jan 2, 2006"},
+			want: map[string]string{"file": "/tmp/tt.json", "round-to": "30", "date-format": "Jan 2, 2006"},
+			wantSources: map[string]string{
+				"file": "env TT_FILE", "round-to": "flag", "date-format": "env TT_DATE_FORMAT",
+			},
+		},
+		{
+			name: "profile",
+			env:  map[string]string{"TT_PROFILE": "acme"},
+			want: map[string]string{"file": "~/acme.json", "round-to": "6", "tag": "billable,remote", "profile": "acme"},
+			wantSources: map[string]string{
+				"file": "profile acme in " + path, "round-to": path, "tag": "profile acme in " + path, "profile": "env TT_PROFILE",
+			},
+		},
+		{
+			name: "environment list",
+			args: []string{"-profile", "acme"},
+			env:  map[string]string{"TT_TAG": "a,b"},
+			want: map[string]string{"tag": "a,b"},
+		},
+		{
+			name:    "unknown profile",
+			args:    []string{"-profile", "initech"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid value",
+			env:     map[string]string{"TT_ROUND_TO": "often"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tags tagsFlag
+			fs := flag.NewFlagSet("tt", flag.ContinueOnError)
+			fs.String("file", "/home/tt/.tt.json", "")
+			fs.Int("round-to", 15, "")
+			fs.Bool("no-split", false, "")
+			fs.String("date-format", "02.01.2006", "")
+			fs.Duration("lock-timeout", 5*time.Second, "")
+			fs.Var(&tags, "tag", "")
+			fs.String("profile", "", "")
+			if err := fs.Parse(tt.args); err != nil {
+				t.Fatal(err)
+			}
+
+			sources, err := configure(fs, path, func(key string) string { return tt.env[key] })
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("configure() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			got := map[string]string{}
+			for name := range tt.want {
+				got[name] = fs.Lookup(name).Value.String()
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("configure() values differ: (-want +got)\n%s", diff)
+			}
+			for name, want := range tt.wantSources {
+				if sources[name] != want {
+					t.Errorf("configure() source of %s = %s, want %s", name, sources[name], want)
+				}
+			}
+		})
+	}
+}
+
+func TestConfigPath(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{name: "default", want: filepath.Join("/home/tt", ".config", "tt", "config.json")},
+		{name: "xdg", env: map[string]string{"XDG_CONFIG_HOME": "/etc/xdg"}, want: filepath.Join("/etc/xdg", "tt", "config.json")},
+		{name: "override", env: map[string]string{"TT_CONFIG": "/tmp/tt.json", "XDG_CONFIG_HOME": "/etc/xdg"}, want: "/tmp/tt.json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := configPath(func(key string) string { return tt.env[key] }, "/home/tt"); got != tt.want {
+				t.Errorf("configPath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/roccoblues/tt/pkg/timesheet"
+)
+
+// edit opens the intervals of a day in the editor and replaces them with
+// the edited intervals.
+func edit(sheet *timesheet.Sheet, args []string) ([]timesheet.Event, error) {
+	fs := flag.NewFlagSet("edit", flag.ExitOnError)
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return nil, fmt.Errorf("edit: missing date")
+	}
+	date, err := parser(sheet).Date(fs.Arg(0))
+	if err != nil {
+		return nil, err
+	}
+
+	indexes := sheet.DayIndexes(date)
+	intervals := sheet.Intervals()
+	var day []timesheet.Interval
+	for _, n := range indexes {
+		day = append(day, intervals[n])
+	}
+
+	var before bytes.Buffer
+	if err := timesheet.NewSheet(sheet.DateFormat, sheet.TimeFormat, day).Save(&before); err != nil {
+		return nil, err
+	}
+	after, err := runEditor(before.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Equal(before.Bytes(), after) {
+		return nil, nil
+	}
+
+	edited, err := timesheet.Load(bytes.NewReader(after), sheet.DateFormat, sheet.TimeFormat)
+	if err != nil {
+		return nil, fmt.Errorf("edit: %s", err)
+	}
+
+	var events []timesheet.Event
+	for i := len(indexes) - 1; i >= 0; i-- {
+		events = append(events, timesheet.Event{Type: timesheet.EventDelete, Index: indexes[i]})
+	}
+	for _, i := range edited.Intervals() {
+		events = append(events, timesheet.Event{Type: timesheet.EventAdd, Interval: i})
+	}
+
+	return events, nil
+}
+
+// runEditor lets the user edit the content in $EDITOR and returns the
+// result.
+func runEditor(content []byte) ([]byte, error) {
+	file, err := ioutil.TempFile("", "tt-*.json")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if err := file.Close(); err != nil {
+		return nil, err
+	}
+
+	editor := strings.Fields(os.Getenv("EDITOR"))
+	if len(editor) == 0 {
+		editor = []string{"vi"}
+	}
+	cmd := exec.Command(editor[0], append(editor[1:], file.Name())...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("edit: %s", err)
+	}
+
+	return ioutil.ReadFile(file.Name())
+}
+
+// amend changes an interval of a day. Without an interval number the last
+// interval of the day is changed. A day without intervals gets a new one.
+func amend(sheet *timesheet.Sheet, args []string) (timesheet.Event, error) {
+	var tags tagsFlag
+	fs := flag.NewFlagSet("amend", flag.ExitOnError)
+	start := fs.String("start", "", "change start time")
+	end := fs.String("end", "", "change end time, empty to reopen the interval")
+	project := fs.String("project", "", "change project")
+	fs.Var(&tags, "tag", "replace tags (repeatable)")
+	note := fs.String("note", "", "replace note")
+	fs.Parse(args)
+
+	if fs.NArg() < 1 || fs.NArg() > 2 {
+		return timesheet.Event{}, fmt.Errorf("amend: expected date and optional interval number")
+	}
+	date, err := parser(sheet).Date(fs.Arg(0))
+	if err != nil {
+		return timesheet.Event{}, err
+	}
+
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	event := timesheet.Event{Type: timesheet.EventEdit}
+	indexes := sheet.DayIndexes(date)
+	switch {
+	case fs.NArg() == 2:
+		if event.Index, err = intervalArg(fs.Arg(1), indexes); err != nil {
+			return timesheet.Event{}, err
+		}
+	case len(indexes) > 0:
+		event.Index = indexes[len(indexes)-1]
+	case set["start"]:
+		event.Type = timesheet.EventAdd
+	default:
+		return timesheet.Event{}, fmt.Errorf("amend: no interval on %s, use -start to add one", fs.Arg(0))
+	}
+
+	interval := timesheet.Interval{}
+	if event.Type == timesheet.EventEdit {
+		interval = sheet.Intervals()[event.Index]
+	}
+	if set["start"] {
+		if interval.Start, err = parser(sheet).TimeOn(date, *start); err != nil {
+			return timesheet.Event{}, err
+		}
+	}
+	if set["end"] {
+		interval.End = time.Time{}
+		if *end != "" {
+			if interval.End, err = parser(sheet).TimeOn(date, *end); err != nil {
+				return timesheet.Event{}, err
+			}
+		}
+	}
+	if set["project"] {
+		interval.Project = *project
+	}
+	if set["tag"] {
+		interval.Tags = tags
+	}
+	if set["note"] {
+		interval.Note = *note
+	}
+	event.Interval = interval
+
+	return event, nil
+}
+
+// remove deletes the n-th interval of a day.
+func remove(sheet *timesheet.Sheet, args []string) (timesheet.Event, error) {
+	if len(args) != 2 {
+		return timesheet.Event{}, fmt.Errorf("delete: expected date and interval number")
+	}
+	date, err := parser(sheet).Date(args[0])
+	if err != nil {
+		return timesheet.Event{}, err
+	}
+
+	n, err := intervalArg(args[1], sheet.DayIndexes(date))
+	if err != nil {
+		return timesheet.Event{}, err
+	}
+
+	return timesheet.Event{Type: timesheet.EventDelete, Index: n}, nil
+}
+
+// intervalArg returns the position of the interval numbered by value,
+// counting from 1 within the day.
+func intervalArg(value string, indexes []int) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 || n > len(indexes) {
+		return 0, fmt.Errorf("invalid interval number '%s', the day has %d intervals", value, len(indexes))
+	}
+	return indexes[n-1], nil
+}
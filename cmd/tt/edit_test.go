@@ -0,0 +1,311 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/roccoblues/tt/pkg/timesheet"
+)
+
+// editSheet returns a sheet with two intervals on March 2 and one on
+// March 3 2026.
+func editSheet() *timesheet.Sheet {
+	at := func(day, hour, min int) time.Time {
+		return time.Date(2026, time.March, day, hour, min, 0, 0, time.Now().Location())
+	}
+	return timesheet.NewSheet("02.01.2006", "15:04", []timesheet.Interval{
+		{Start: at(2, 9, 0), End: at(2, 12, 0), Project: "acme"},
+		{Start: at(2, 13, 0), End: at(2, 17, 30), Note: "review"},
+		{Start: at(3, 8, 0), End: at(3, 16, 0)},
+	})
+}
+
+func TestIntervalArg(t *testing.T) {
+	indexes := []int{2, 5, 7}
+
+	tests := []struct {
+		value   string
+		want    int
+		wantErr bool
+	}{
+		{value: "1", want: 2},
+		{value: "3", want: 7},
+		{value: "0", wantErr: true},
+		{value: "-1", wantErr: true},
+		{value: "4", wantErr: true},
+		{value: "x", wantErr: true},
+		{value: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := intervalArg(tt.value, indexes)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("intervalArg() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("intervalArg() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemove(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    timesheet.Event
+		wantErr bool
+	}{
+		{
+			name: "second of the day",
+			args: []string{"02.03.2026", "2"},
+			want: timesheet.Event{Type: timesheet.EventDelete, Index: 1},
+		},
+		{
+			name: "first of the next day",
+			args: []string{"03.03.2026", "1"},
+			want: timesheet.Event{Type: timesheet.EventDelete, Index: 2},
+		},
+		{
+			name:    "negative",
+			args:    []string{"02.03.2026", "-1"},
+			wantErr: true,
+		},
+		{
+			name:    "out of range",
+			args:    []string{"02.03.2026", "3"},
+			wantErr: true,
+		},
+		{
+			name:    "empty day",
+			args:    []string{"04.03.2026", "1"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid number",
+			args:    []string{"02.03.2026", "two"},
+			wantErr: true,
+		},
+		{
+			name:    "missing number",
+			args:    []string{"02.03.2026"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid date",
+			args:    []string{"32.03.2026", "1"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := remove(editSheet(), tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("remove() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("remove() differs: (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestAmend(t *testing.T) {
+	sheet := editSheet()
+	intervals := sheet.Intervals()
+	at := func(day, hour, min int) time.Time {
+		return time.Date(2026, time.March, day, hour, min, 0, 0, time.Now().Location())
+	}
+	with := func(i timesheet.Interval, change func(*timesheet.Interval)) timesheet.Interval {
+		change(&i)
+		return i
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    timesheet.Event
+		wantErr bool
+	}{
+		{
+			name: "last of the day",
+			args: []string{"-note", "planning", "02.03.2026"},
+			want: timesheet.Event{Type: timesheet.EventEdit, Index: 1, Interval: with(intervals[1], func(i *timesheet.Interval) {
+				i.Note = "planning"
+			})},
+		},
+		{
+			name: "numbered",
+			args: []string{"-start", "08:30", "-end", "11:45", "-tag", "call", "02.03.2026", "1"},
+			want: timesheet.Event{Type: timesheet.EventEdit, Index: 0, Interval: with(intervals[0], func(i *timesheet.Interval) {
+				i.Start = at(2, 8, 30)
+				i.End = at(2, 11, 45)
+				i.Tags = []string{"call"}
+			})},
+		},
+		{
+			name: "reopen",
+			args: []string{"-end", "", "03.03.2026"},
+			want: timesheet.Event{Type: timesheet.EventEdit, Index: 2, Interval: with(intervals[2], func(i *timesheet.Interval) {
+				i.End = time.Time{}
+			})},
+		},
+		{
+			name: "add to empty day",
+			args: []string{"-start", "09:00", "-end", "10:00", "-project", "acme", "04.03.2026"},
+			want: timesheet.Event{Type: timesheet.EventAdd, Interval: timesheet.Interval{Start: at(4, 9, 0), End: at(4, 10, 0), Project: "acme"}},
+		},
+		{
+			name:    "empty day without start",
+			args:    []string{"-end", "10:00", "04.03.2026"},
+			wantErr: true,
+		},
+		{
+			name:    "numbered on empty day",
+			args:    []string{"-start", "09:00", "04.03.2026", "1"},
+			wantErr: true,
+		},
+		{
+			name:    "negative",
+			args:    []string{"-note", "x", "02.03.2026", "-1"},
+			wantErr: true,
+		},
+		{
+			name:    "out of range",
+			args:    []string{"-note", "x", "02.03.2026", "3"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid number",
+			args:    []string{"-note", "x", "02.03.2026", "last"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid time",
+			args:    []string{"-start", "25:00", "02.03.2026"},
+			wantErr: true,
+		},
+		{
+			name:    "missing date",
+			args:    []string{"-note", "x"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := amend(editSheet(), tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("amend() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("amend() differs: (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestEdit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// the test binary is the editor, see TestMain
+	defer os.Setenv("EDITOR", os.Getenv("EDITOR"))
+	os.Setenv("EDITOR", os.Args[0])
+	defer os.Unsetenv("TT_TEST_EDITOR")
+	os.Setenv("TT_TEST_EDITOR", dir)
+
+	save := func(intervals []timesheet.Interval) []byte {
+		var b bytes.Buffer
+		if err := timesheet.NewSheet("02.01.2006", "15:04", intervals).Save(&b); err != nil {
+			t.Fatal(err)
+		}
+		return b.Bytes()
+	}
+	sheet := editSheet()
+	intervals := sheet.Intervals()
+	edited := timesheet.Interval{
+		Start: time.Date(2026, time.March, 2, 9, 15, 0, 0, time.Now().Location()),
+		End:   time.Date(2026, time.March, 2, 17, 0, 0, 0, time.Now().Location()),
+		Note:  "merged",
+	}
+	added := timesheet.Interval{
+		Start: time.Date(2026, time.March, 4, 10, 0, 0, 0, time.Now().Location()),
+		End:   time.Date(2026, time.March, 4, 11, 0, 0, 0, time.Now().Location()),
+	}
+
+	tests := []struct {
+		name       string
+		date       string
+		after      []byte // Content written by the editor, unchanged if nil.
+		wantBefore []byte
+		want       []timesheet.Event
+		wantErr    bool
+	}{
+		{
+			name:       "unchanged",
+			date:       "02.03.2026",
+			wantBefore: save(intervals[:2]),
+		},
+		{
+			name:       "replace day",
+			date:       "02.03.2026",
+			after:      save([]timesheet.Interval{edited}),
+			wantBefore: save(intervals[:2]),
+			want: []timesheet.Event{
+				{Type: timesheet.EventDelete, Index: 1},
+				{Type: timesheet.EventDelete, Index: 0},
+				{Type: timesheet.EventAdd, Interval: edited},
+			},
+		},
+		{
+			name:       "add to empty day",
+			date:       "04.03.2026",
+			after:      save([]timesheet.Interval{added}),
+			wantBefore: save(nil),
+			want:       []timesheet.Event{{Type: timesheet.EventAdd, Interval: added}},
+		},
+		{
+			name:       "invalid",
+			date:       "03.03.2026",
+			after:      []byte("[{"),
+			wantBefore: save(intervals[2:]),
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			after := filepath.Join(dir, "after.json")
+			os.Remove(after)
+			if tt.after != nil {
+				if err := ioutil.WriteFile(after, tt.after, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := edit(sheet, []string{tt.date})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("edit() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("edit() differs: (-want +got)\n%s", diff)
+			}
+
+			before, err := ioutil.ReadFile(filepath.Join(dir, "before.json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(string(tt.wantBefore), string(before)); diff != "" {
+				t.Errorf("edit() editor content differs: (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,415 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/roccoblues/tt/pkg/ical"
+	"github.com/roccoblues/tt/pkg/timesheet"
+	"github.com/roccoblues/tt/pkg/timewarrior"
+)
+
+// csvFlags defines the flags describing the layout of a CSV file. The
+// returned function reads the format after the flags are parsed.
+func csvFlags(fs *flag.FlagSet) func() (timesheet.CSVFormat, error) {
+	def := timesheet.DefaultCSVFormat()
+	columns := fs.String("columns", "", "order of the columns, any of "+strings.Join(timesheet.CSVColumns, ", ")+" (default header or all)")
+	delimiter := fs.String("delimiter", string(def.Comma), "field delimiter, 'tab' for tabs")
+	dateFormat := fs.String("date-format", def.DateFormat, "format of the date column")
+	timeFormat := fs.String("time-format", def.TimeFormat, "format of the start and end columns")
+	header := fs.Bool("header", def.Header, "first row names the columns")
+
+	return func() (timesheet.CSVFormat, error) {
+		format := timesheet.CSVFormat{
+			DateFormat: *dateFormat,
+			TimeFormat: *timeFormat,
+			Header:     *header,
+		}
+		if *columns != "" {
+			for _, c := range strings.Split(*columns, ",") {
+				format.Columns = append(format.Columns, strings.TrimSpace(c))
+			}
+		}
+
+		d := *delimiter
+		if d == "tab" || d == `\t` {
+			d = "\t"
+		}
+		r, size := utf8.DecodeRuneInString(d)
+		if size == 0 || size != len(d) || r == '"' || r == '\r' || r == '\n' || r == utf8.RuneError {
+			return format, fmt.Errorf("invalid delimiter '%s'", *delimiter)
+		}
+		format.Comma = r
+
+		return format, nil
+	}
+}
+
+// export writes the intervals matching the filter as CSV, iCalendar or
+// Timewarrior data.
+func export(sheet *timesheet.Sheet, args []string, filter timesheet.Filter, w io.Writer) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	kind := fs.String("format", "csv", "format of the exported data: 'csv', 'ics' or 'timewarrior'")
+	dir := fs.String("dir", "", "write monthly Timewarrior data files into the directory")
+	csvFormat := csvFlags(fs)
+	fs.Parse(args)
+
+	if fs.NArg() > 0 {
+		return fmt.Errorf("export: unexpected argument '%s'", fs.Arg(0))
+	}
+
+	var intervals []timesheet.Interval
+	for _, i := range sheet.Intervals() {
+		if filter.Match(i) {
+			intervals = append(intervals, i)
+		}
+	}
+
+	switch *kind {
+	case "csv":
+		format, err := csvFormat()
+		if err != nil {
+			return fmt.Errorf("export: %s", err)
+		}
+		format.Location = sheet.Location
+		return timesheet.WriteCSV(w, intervals, format)
+	case "ics":
+		stamp := time.Now()
+		var events []ical.Event
+		for _, i := range intervals {
+			// open intervals have no end yet
+			if !i.Open() {
+				events = append(events, calendarEvent(i, stamp))
+			}
+		}
+		return ical.Write(w, events)
+	case "timewarrior":
+		var data []timewarrior.Interval
+		for _, i := range intervals {
+			data = append(data, timewarriorInterval(i))
+		}
+		if *dir == "" {
+			return timewarrior.Write(w, data)
+		}
+		return writeTimewarrior(*dir, data)
+	default:
+		return fmt.Errorf("export: unknown format '%s'", *kind)
+	}
+}
+
+// projectTag is the prefix of the Timewarrior tag holding the project of an
+// interval.
+const projectTag = "project:"
+
+// timewarriorInterval returns the Timewarrior interval of an interval. The
+// project is the first tag, the note is the annotation.
+func timewarriorInterval(i timesheet.Interval) timewarrior.Interval {
+	var tags []string
+	if i.Project != "" {
+		tags = append(tags, projectTag+i.Project)
+	}
+	return timewarrior.Interval{
+		Start:      i.Start,
+		End:        i.End,
+		Tags:       append(tags, i.Tags...),
+		Annotation: i.Note,
+	}
+}
+
+// writeTimewarrior writes the intervals into the monthly data files of a
+// Timewarrior data directory. Existing files aren't overwritten.
+func writeTimewarrior(dir string, intervals []timewarrior.Interval) error {
+	months := map[string][]timewarrior.Interval{}
+	var names []string
+	for _, i := range intervals {
+		name := timewarrior.FileName(i.Start)
+		if _, ok := months[name]; !ok {
+			names = append(names, name)
+		}
+		months[name] = append(months[name], i)
+	}
+
+	for _, name := range names {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return fmt.Errorf("export: %s already exists", filepath.Join(dir, name))
+		}
+	}
+	for _, name := range names {
+		file, err := os.OpenFile(filepath.Join(dir, name), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err != nil {
+			return err
+		}
+		err = timewarrior.Write(file, months[name])
+		if cerr := file.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// calendarEvent returns the calendar event of an interval. The summary is
+// the project and the note, the categories are the tags.
+func calendarEvent(i timesheet.Interval, stamp time.Time) ical.Event {
+	summary := "Work"
+	switch {
+	case i.Project != "" && i.Note != "":
+		summary = i.Project + ": " + i.Note
+	case i.Project != "":
+		summary = i.Project
+	case i.Note != "":
+		summary = i.Note
+	}
+
+	uid := i.UID
+	if uid == "" {
+		uid = calendarUID(i)
+	}
+
+	return ical.Event{
+		UID:        uid,
+		Summary:    summary,
+		Categories: i.Tags,
+		Start:      i.Start,
+		End:        i.End,
+		Stamp:      stamp,
+	}
+}
+
+// calendarUID returns the UID of the calendar event of an interval which
+// wasn't imported from a calendar. Intervals don't overlap, so the start is
+// unique.
+func calendarUID(i timesheet.Interval) string {
+	return i.Start.UTC().Format("20060102T150405Z") + "@tt"
+}
+
+// importRow is an interval read from a file to import.
+type importRow struct {
+	source   string // Where the interval is in the file (ie. "row 3").
+	interval timesheet.Interval
+	err      error // Why the row is no valid interval.
+}
+
+// importIntervals adds the intervals of a CSV, iCalendar or Timewarrior data
+// file or of all monthly files of a Timewarrior data directory. Rows which
+// are no valid interval or overlap existing intervals or earlier rows are
+// skipped and reported to w. Calendar events imported before are skipped
+// as well, so importing the same calendar again doesn't change anything.
+func importIntervals(sheet *timesheet.Sheet, args []string, w io.Writer) ([]timesheet.Event, error) {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	kind := fs.String("format", "", "format of the imported file: 'csv', 'ics' or 'timewarrior' (default from file extension)")
+	csvFormat := csvFlags(fs)
+	category := fs.String("category", "", "only import calendar events of the category")
+	project := fs.String("project", "", "set project of imported calendar events and Timewarrior intervals")
+	var tags tagsFlag
+	fs.Var(&tags, "tag", "tag imported calendar events and Timewarrior intervals (repeatable)")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return nil, fmt.Errorf("import: missing CSV, iCalendar or Timewarrior file")
+	}
+	name := fs.Arg(0)
+	info, err := os.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+	if *kind == "" {
+		switch strings.ToLower(filepath.Ext(name)) {
+		case ".ics", ".ical":
+			*kind = "ics"
+		case ".data":
+			*kind = "timewarrior"
+		default:
+			*kind = "csv"
+		}
+		if info.IsDir() {
+			*kind = "timewarrior"
+		}
+	}
+
+	var rows []importRow
+	unit := "rows"
+	switch *kind {
+	case "timewarrior":
+		if rows, err = readTimewarrior(name, info.IsDir(), *project, tags); err != nil {
+			return nil, err
+		}
+		// Timewarrior times are UTC, use the time zone of the sheet instead
+		for n := range rows {
+			rows[n].interval = rows[n].interval.In(location(sheet))
+		}
+		unit = "intervals"
+	case "csv":
+		format, err := csvFormat()
+		if err != nil {
+			return nil, fmt.Errorf("import: %s", err)
+		}
+		format.Location = sheet.Location
+		file, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		csvRows, err := timesheet.ReadCSV(file, format)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", name, err)
+		}
+		for _, row := range csvRows {
+			rows = append(rows, importRow{source: fmt.Sprintf("row %d", row.Row), interval: row.Interval, err: row.Err})
+		}
+	case "ics":
+		file, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		calendarEvents, err := ical.Parse(file)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", name, err)
+		}
+		for n, e := range calendarEvents {
+			if *category != "" && !e.HasCategory(*category) {
+				continue
+			}
+			row := importRow{source: fmt.Sprintf("event %d", n+1)}
+			row.interval, row.err = calendarInterval(e, location(sheet), *project, tags)
+			rows = append(rows, row)
+		}
+		unit = "events"
+	default:
+		return nil, fmt.Errorf("import: unknown format '%s'", *kind)
+	}
+
+	// validate every row against the existing intervals and the rows
+	// imported before it
+	imported := timesheet.NewSheet(sheet.DateFormat, sheet.TimeFormat, sheet.Intervals())
+	known := map[string]bool{}
+	for _, i := range sheet.Intervals() {
+		known[calendarUID(i)] = true
+		if i.UID != "" {
+			known[i.UID] = true
+		}
+	}
+
+	var events []timesheet.Event
+	var skipped, duplicates int
+	for _, row := range rows {
+		if uid := row.interval.UID; row.err == nil && uid != "" && known[uid] {
+			duplicates++
+			continue
+		}
+		err := row.err
+		if err == nil {
+			err = imported.Insert(row.interval)
+		}
+		if err != nil {
+			fmt.Fprintf(w, "%s: %s skipped: %s\n", name, row.source, err)
+			skipped++
+			continue
+		}
+		if row.interval.UID != "" {
+			known[row.interval.UID] = true
+		}
+		events = append(events, timesheet.Event{Type: timesheet.EventAdd, Interval: row.interval})
+	}
+	fmt.Fprintf(w, "%s: %d intervals imported, %d %s skipped", name, len(events), skipped, unit)
+	if duplicates > 0 {
+		fmt.Fprintf(w, ", %d already imported", duplicates)
+	}
+	fmt.Fprintln(w)
+
+	return events, nil
+}
+
+// calendarInterval returns the interval of a calendar event. The note is
+// the summary of the event. UTC and local times are converted to loc, times
+// with a time zone of their own keep it.
+func calendarInterval(e ical.Event, loc *time.Location, project string, tags []string) (timesheet.Interval, error) {
+	if e.AllDay {
+		return timesheet.Interval{}, fmt.Errorf("all-day event '%s'", e.Summary)
+	}
+	if e.End.IsZero() {
+		return timesheet.Interval{}, fmt.Errorf("event '%s' without end", e.Summary)
+	}
+
+	note := e.Summary
+	if note == "" {
+		note = e.Description
+	}
+	return timesheet.Interval{
+		Start:   calendarTime(e.Start, loc),
+		End:     calendarTime(e.End, loc),
+		Project: project,
+		Tags:    tags,
+		Note:    note,
+		UID:     e.UID,
+	}, nil
+}
+
+// calendarTime converts a UTC or local calendar time to loc.
+func calendarTime(t time.Time, loc *time.Location) time.Time {
+	if t.Location() == time.UTC || t.Location() == time.Local {
+		return t.In(loc)
+	}
+	return t
+}
+
+// readTimewarrior reads the intervals of a Timewarrior data file or of all
+// monthly files of a data directory.
+func readTimewarrior(name string, dir bool, project string, tags []string) ([]importRow, error) {
+	files := []string{name}
+	if dir {
+		var err error
+		if files, err = filepath.Glob(filepath.Join(name, "[0-9][0-9][0-9][0-9]-[0-9][0-9].data")); err != nil {
+			return nil, err
+		}
+	}
+
+	var rows []importRow
+	for _, f := range files {
+		file, err := os.Open(f)
+		if err != nil {
+			return nil, err
+		}
+		intervals, err := timewarrior.Parse(file)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", f, err)
+		}
+
+		for n, i := range intervals {
+			source := fmt.Sprintf("interval %d", n+1)
+			if dir {
+				source = filepath.Base(f) + " " + source
+			}
+			rows = append(rows, importRow{source: source, interval: timesheetInterval(i, project, tags)})
+		}
+	}
+
+	return rows, nil
+}
+
+// timesheetInterval returns the interval of a Timewarrior interval. A tag
+// with the project prefix sets the project, otherwise the project is the
+// given one.
+func timesheetInterval(i timewarrior.Interval, project string, tags []string) timesheet.Interval {
+	interval := timesheet.Interval{Start: i.Start, End: i.End, Project: project, Note: i.Annotation}
+	for _, tag := range i.Tags {
+		if strings.HasPrefix(tag, projectTag) && len(tag) > len(projectTag) {
+			interval.Project = strings.TrimPrefix(tag, projectTag)
+			continue
+		}
+		interval.Tags = append(interval.Tags, tag)
+	}
+	interval.Tags = append(interval.Tags, tags...)
+	return interval
+}
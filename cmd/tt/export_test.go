@@ -0,0 +1,325 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/roccoblues/tt/pkg/ical"
+	"github.com/roccoblues/tt/pkg/timesheet"
+)
+
+func TestExport(t *testing.T) {
+	loc := time.Now().Location()
+	sheet := timesheet.NewSheet("02.01.2006", "15:04", []timesheet.Interval{
+		{Start: time.Date(2026, time.March, 2, 9, 0, 0, 0, loc), End: time.Date(2026, time.March, 2, 12, 0, 0, 0, loc), Project: "acme"},
+		{Start: time.Date(2026, time.March, 3, 9, 0, 0, 0, loc), End: time.Date(2026, time.March, 3, 12, 0, 0, 0, loc), Note: "meeting"},
+	})
+
+	var got strings.Builder
+	args := []string{"-delimiter", "tab", "-columns", "date,start,end,note", "-date-format", "02.01.2006", "-header=false"}
+	if err := export(sheet, args, timesheet.Filter{Project: "acme"}, &got); err != nil {
+		t.Fatalf("export() error = %v", err)
+	}
+	if want := "02.03.2026\t09:00\t12:00\t\n"; got.String() != want {
+		t.Errorf("export() = %q, want %q", got.String(), want)
+	}
+
+	for _, args := range [][]string{{"-format", "xls"}, {"-delimiter", ";;"}, {"-columns", "date,hours"}} {
+		if err := export(sheet, args, timesheet.Filter{}, &got); err == nil {
+			t.Errorf("export(%v) succeeded", args)
+		}
+	}
+}
+
+func TestImportCSV(t *testing.T) {
+	loc := time.Now().Location()
+	sheet := timesheet.NewSheet("02.01.2006", "15:04", []timesheet.Interval{
+		{Start: time.Date(2026, time.March, 3, 9, 0, 0, 0, loc), End: time.Date(2026, time.March, 3, 12, 0, 0, 0, loc)},
+	})
+
+	var report strings.Builder
+	events, err := importIntervals(sheet, []string{"-delimiter", ";", "testdata/import.csv"}, &report)
+	if err != nil {
+		t.Fatalf("importIntervals() error = %v", err)
+	}
+
+	want := []timesheet.Event{
+		{Type: timesheet.EventAdd, Interval: timesheet.Interval{Start: time.Date(2026, time.March, 2, 9, 0, 0, 0, loc), End: time.Date(2026, time.March, 2, 12, 0, 0, 0, loc), Project: "acme", Note: "planning"}},
+		{Type: timesheet.EventAdd, Interval: timesheet.Interval{Start: time.Date(2026, time.March, 4, 8, 0, 0, 0, loc), Project: "acme"}},
+	}
+	if diff := cmp.Diff(want, events); diff != "" {
+		t.Errorf("importIntervals() differs: (-want +got)\n%s", diff)
+	}
+
+	wantReport := `testdata/import.csv: row 3 skipped: interval 02.03.2026 11:00-13:00 overlaps 02.03.2026 09:00-12:00
+testdata/import.csv: row 4 skipped: interval 03.03.2026 10:00-11:00 overlaps 03.03.2026 09:00-12:00
+testdata/import.csv: row 6 skipped: expected 5 columns, got 2
+testdata/import.csv: 2 intervals imported, 3 rows skipped
+`
+	if diff := cmp.Diff(wantReport, report.String()); diff != "" {
+		t.Errorf("importIntervals() report differs: (-want +got)\n%s", diff)
+	}
+}
+
+func TestExportICS(t *testing.T) {
+	at := func(day, hour, min int) time.Time {
+		return time.Date(2026, time.March, day, hour, min, 0, 0, time.UTC).In(time.Now().Location())
+	}
+	sheet := timesheet.NewSheet("02.01.2006", "15:04", []timesheet.Interval{
+		{Start: at(2, 7, 0), End: at(2, 7, 30), Note: "Standup", UID: "standup-0302@calendar.example"},
+		{Start: at(2, 8, 0), End: at(2, 11, 0), Project: "acme", Tags: []string{"dev", "billable"}, Note: "invoices, part 2"},
+		{Start: at(3, 8, 0), End: at(3, 9, 0), Project: "acme"},
+		{Start: at(3, 9, 0)},
+	})
+
+	var got strings.Builder
+	if err := export(sheet, []string{"-format", "ics"}, timesheet.Filter{}, &got); err != nil {
+		t.Fatalf("export() error = %v", err)
+	}
+	events, err := ical.Parse(strings.NewReader(got.String()))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	for n := range events {
+		events[n].Stamp = time.Time{}
+	}
+
+	want := []ical.Event{
+		{UID: "standup-0302@calendar.example", Summary: "Standup", Start: at(2, 7, 0), End: at(2, 7, 30)},
+		{UID: "20260302T080000Z@tt", Summary: "acme: invoices, part 2", Categories: []string{"dev", "billable"}, Start: at(2, 8, 0), End: at(2, 11, 0)},
+		{UID: "20260303T080000Z@tt", Summary: "acme", Start: at(3, 8, 0), End: at(3, 9, 0)},
+	}
+	if diff := cmp.Diff(want, events); diff != "" {
+		t.Errorf("export() differs: (-want +got)\n%s", diff)
+	}
+
+	// importing the export into the same sheet doesn't change anything
+	dir, err := ioutil.TempDir("", "tt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "tt.ics")
+	if err := ioutil.WriteFile(file, []byte(got.String()), 0600); err != nil {
+		t.Fatal(err)
+	}
+	var report strings.Builder
+	imported, err := importIntervals(sheet, []string{file}, &report)
+	if err != nil {
+		t.Fatalf("importIntervals() error = %v", err)
+	}
+	if len(imported) > 0 {
+		t.Errorf("importIntervals() = %v, want no events", imported)
+	}
+	if want := file + ": 0 intervals imported, 0 events skipped, 3 already imported\n"; report.String() != want {
+		t.Errorf("importIntervals() report = %q, want %q", report.String(), want)
+	}
+}
+
+func TestImportICS(t *testing.T) {
+	at := func(day, hour, min int) time.Time {
+		return time.Date(2026, time.March, day, hour, min, 0, 0, time.UTC).In(time.Now().Location())
+	}
+	sheet := timesheet.NewSheet("02.01.2006", "15:04", []timesheet.Interval{
+		{Start: at(5, 9, 0), End: at(5, 10, 0), Note: "Planning", UID: "planning@calendar.example"},
+	})
+
+	args := []string{"-category", "work", "-project", "acme", "-tag", "meeting", "testdata/calendar.ics"}
+	var report strings.Builder
+	events, err := importIntervals(sheet, args, &report)
+	if err != nil {
+		t.Fatalf("importIntervals() error = %v", err)
+	}
+
+	want := []timesheet.Event{
+		{Type: timesheet.EventAdd, Interval: timesheet.Interval{Start: at(2, 7, 0), End: at(2, 7, 30), Project: "acme", Tags: []string{"meeting"}, Note: "Standup", UID: "standup-0302@calendar.example"}},
+		{Type: timesheet.EventAdd, Interval: timesheet.Interval{Start: at(3, 8, 0), End: at(3, 9, 0), Project: "acme", Tags: []string{"meeting"}, Note: "Sprint review", UID: "review@calendar.example"}},
+	}
+	if diff := cmp.Diff(want, events); diff != "" {
+		t.Errorf("importIntervals() differs: (-want +got)\n%s", diff)
+	}
+	wantReport := `testdata/calendar.ics: event 4 skipped: all-day event 'Team offsite'
+testdata/calendar.ics: 2 intervals imported, 1 events skipped, 1 already imported
+`
+	if diff := cmp.Diff(wantReport, report.String()); diff != "" {
+		t.Errorf("importIntervals() report differs: (-want +got)\n%s", diff)
+	}
+
+	// importing the calendar again doesn't add anything
+	for _, e := range events {
+		if err := sheet.Insert(e.Interval); err != nil {
+			t.Fatalf("Insert() error = %v", err)
+		}
+	}
+	report.Reset()
+	events, err = importIntervals(sheet, args, &report)
+	if err != nil {
+		t.Fatalf("importIntervals() error = %v", err)
+	}
+	if len(events) > 0 {
+		t.Errorf("importIntervals() = %v, want no events", events)
+	}
+	wantReport = `testdata/calendar.ics: event 4 skipped: all-day event 'Team offsite'
+testdata/calendar.ics: 0 intervals imported, 1 events skipped, 3 already imported
+`
+	if diff := cmp.Diff(wantReport, report.String()); diff != "" {
+		t.Errorf("importIntervals() report differs: (-want +got)\n%s", diff)
+	}
+}
+
+func TestCalendarInterval(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone database not available: %s", err)
+	}
+	sheetLoc := time.FixedZone("", 2*60*60)
+
+	tests := []struct {
+		name      string
+		start     time.Time
+		wantStart time.Time
+	}{
+		{"UTC", time.Date(2026, time.March, 2, 7, 0, 0, 0, time.UTC), time.Date(2026, time.March, 2, 9, 0, 0, 0, sheetLoc)},
+		{"local", time.Date(2026, time.March, 2, 7, 0, 0, 0, time.Local), time.Date(2026, time.March, 2, 7, 0, 0, 0, time.Local).In(sheetLoc)},
+		{"time zone", time.Date(2026, time.March, 2, 7, 0, 0, 0, newYork), time.Date(2026, time.March, 2, 7, 0, 0, 0, newYork)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := ical.Event{Summary: "Standup", Start: tt.start, End: tt.start.Add(30 * time.Minute)}
+			got, err := calendarInterval(e, sheetLoc, "", nil)
+			if err != nil {
+				t.Fatalf("calendarInterval() error = %v", err)
+			}
+			if !got.Start.Equal(tt.wantStart) || got.Start.Location().String() != tt.wantStart.Location().String() {
+				t.Errorf("calendarInterval() start = %v, want %v", got.Start, tt.wantStart)
+			}
+			if got.End.Location().String() != tt.wantStart.Location().String() {
+				t.Errorf("calendarInterval() end = %v, want in %v", got.End, tt.wantStart.Location())
+			}
+		})
+	}
+}
+
+func TestExportTimewarrior(t *testing.T) {
+	at := func(month time.Month, day, hour, min int) time.Time {
+		return time.Date(2026, month, day, hour, min, 0, 0, time.UTC).In(time.Now().Location())
+	}
+	intervals := []timesheet.Interval{
+		{Start: at(time.January, 30, 8, 0), End: at(time.January, 30, 12, 0), Project: "acme", Tags: []string{"dev", "code review"}, Note: "fixing invoices"},
+		{Start: at(time.February, 2, 9, 0), End: at(time.February, 2, 10, 0), Note: "planning"},
+		{Start: at(time.February, 2, 11, 0)},
+	}
+	sheet := timesheet.NewSheet("02.01.2006", "15:04", intervals)
+
+	var got strings.Builder
+	if err := export(sheet, []string{"-format", "timewarrior"}, timesheet.Filter{}, &got); err != nil {
+		t.Fatalf("export() error = %v", err)
+	}
+	want := `inc 20260130T080000Z - 20260130T120000Z # project:acme dev "code review" # "fixing invoices"
+inc 20260202T090000Z - 20260202T100000Z # # "planning"
+inc 20260202T110000Z
+`
+	if diff := cmp.Diff(want, got.String()); diff != "" {
+		t.Errorf("export() differs: (-want +got)\n%s", diff)
+	}
+
+	dir, err := ioutil.TempDir("", "tt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := export(sheet, []string{"-format", "timewarrior", "-dir", dir}, timesheet.Filter{}, &got); err != nil {
+		t.Fatalf("export() error = %v", err)
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{filepath.Join(dir, "2026-01.data"), filepath.Join(dir, "2026-02.data")}, files); diff != "" {
+		t.Errorf("export() files differ: (-want +got)\n%s", diff)
+	}
+	if err := export(sheet, []string{"-format", "timewarrior", "-dir", dir}, timesheet.Filter{}, &got); err == nil {
+		t.Errorf("export() overwrote existing files")
+	}
+
+	// importing the data directory results in the same intervals
+	var report strings.Builder
+	events, err := importIntervals(timesheet.NewSheet("02.01.2006", "15:04", nil), []string{dir}, &report)
+	if err != nil {
+		t.Fatalf("importIntervals() error = %v", err)
+	}
+	var imported []timesheet.Interval
+	for _, e := range events {
+		imported = append(imported, e.Interval)
+	}
+	if diff := cmp.Diff(intervals, imported); diff != "" {
+		t.Errorf("importIntervals() differs: (-want +got)\n%s", diff)
+	}
+}
+
+func TestImportTimewarrior(t *testing.T) {
+	at := func(month time.Month, day, hour, min int) time.Time {
+		return time.Date(2026, month, day, hour, min, 0, 0, time.UTC).In(time.Now().Location())
+	}
+	sheet := timesheet.NewSheet("02.01.2006", "15:04", nil)
+	span := func(start, end time.Time) string {
+		return start.Format("02.01.2006 15:04") + "-" + end.Format("15:04")
+	}
+	overlap := "interval " + span(at(time.January, 5, 11, 0), at(time.January, 5, 13, 0)) + " overlaps " + span(at(time.January, 5, 8, 0), at(time.January, 5, 12, 0))
+
+	tests := []struct {
+		name       string
+		args       []string
+		want       []timesheet.Interval
+		wantReport string
+	}{
+		{
+			name: "file",
+			args: []string{"-project", "internal", "testdata/timewarrior/2026-01.data"},
+			want: []timesheet.Interval{
+				{Start: at(time.January, 5, 8, 0), End: at(time.January, 5, 12, 0), Project: "acme", Tags: []string{"review"}, Note: "fixing invoices"},
+				{Start: at(time.January, 6, 7, 30), End: at(time.January, 6, 8, 0), Project: "internal", Tags: []string{"standup", "code review"}},
+			},
+			wantReport: "testdata/timewarrior/2026-01.data: interval 2 skipped: " + overlap + "\n" +
+				"testdata/timewarrior/2026-01.data: 2 intervals imported, 1 intervals skipped\n",
+		},
+		{
+			name: "directory",
+			args: []string{"-tag", "timew", "testdata/timewarrior"},
+			want: []timesheet.Interval{
+				{Start: at(time.January, 5, 8, 0), End: at(time.January, 5, 12, 0), Project: "acme", Tags: []string{"review", "timew"}, Note: "fixing invoices"},
+				{Start: at(time.January, 6, 7, 30), End: at(time.January, 6, 8, 0), Tags: []string{"standup", "code review", "timew"}},
+				{Start: at(time.February, 2, 9, 0), End: at(time.February, 2, 10, 0), Tags: []string{"timew"}, Note: "planning"},
+			},
+			wantReport: "testdata/timewarrior: 2026-01.data interval 2 skipped: " + overlap + "\n" +
+				"testdata/timewarrior: 3 intervals imported, 1 intervals skipped\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var report strings.Builder
+			events, err := importIntervals(sheet, tt.args, &report)
+			if err != nil {
+				t.Fatalf("importIntervals() error = %v", err)
+			}
+			var got []timesheet.Interval
+			for _, e := range events {
+				got = append(got, e.Interval)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("importIntervals() differs: (-want +got)\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantReport, report.String()); diff != "" {
+				t.Errorf("importIntervals() report differs: (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
@@ -3,15 +3,79 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/roccoblues/tt/pkg/sqlite"
+	"github.com/roccoblues/tt/pkg/timeparse"
 	"github.com/roccoblues/tt/pkg/timesheet"
 )
 
 const defaultFileName = ".tt.json"
 
+// readOnlyCommands don't modify the data file.
+var readOnlyCommands = map[string]bool{
+	"balance": true,
+	"config":  true,
+	"export":  true,
+	"report":  true,
+	"status":  true,
+}
+
+// readOnly reports whether the command given by args doesn't modify the data
+// file.
+func readOnly(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	if args[0] == "absence" {
+		return len(args) > 1 && args[1] == "list"
+	}
+	return readOnlyCommands[args[0]]
+}
+
+const usage = `Usage: %[1]s [flags] [start [-project name] [-tag name]...|stop] [time] [note]
+       %[1]s [flags] add [-project name] [-tag name]... [-note text] date start-end...
+       %[1]s [flags] edit date
+       %[1]s [flags] amend [-start time] [-end time] [-project name] [-tag name]... [-note text] date [n]
+       %[1]s [flags] delete date n
+       %[1]s [flags] absence add [-half] [-note text] vacation|sick|holiday|comp-time date[..date]
+       %[1]s [flags] absence delete date[..date] [type]
+       %[1]s [flags] absence import [-type type] [-category name] file.ics
+       %[1]s [flags] absence list [-year year]
+       %[1]s [flags] cancel
+       %[1]s [flags] undo
+       %[1]s [flags] migrate [-to json|journal|sqlite] file
+       %[1]s [flags] restore [n]
+       %[1]s [flags] compact
+       %[1]s [flags] export [-format csv|ics|timewarrior] [-dir dir] [-columns list] [-delimiter char] [-header=false]
+       %[1]s [flags] import [-columns list] [-delimiter char] [-header=false] file.csv
+       %[1]s [flags] import [-category name] [-project name] [-tag name]... file.ics
+       %[1]s [flags] import [-project name] [-tag name]... file.data|dir
+       %[1]s [flags] report [-from date [-to date]|-week|-month|-year|-last 4w]
+       %[1]s [flags] balance [since]
+       %[1]s [flags] status [-format text|json]
+       %[1]s [flags] config
+
+`
+
+// tagsFlag collects the values of a repeatable flag.
+type tagsFlag []string
+
+func (t *tagsFlag) String() string {
+	return strings.Join(*t, ",")
+}
+
+func (t *tagsFlag) Set(value string) error {
+	*t = append(*t, value)
+	return nil
+}
+
 func main() {
 	home, err := os.UserHomeDir()
 	if err != nil {
@@ -20,80 +84,389 @@ func main() {
 	}
 
 	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: %s [flags] [start|stop] [time]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, usage, os.Args[0])
 		flag.PrintDefaults()
 	}
 
+	var flagTags tagsFlag
 	flagFile := flag.String("file", filepath.Join(home, defaultFileName), "path to data file")
-	flagMonth := flag.Int("month", 0, "output month (default current)")
+	flagBackups := flag.Int("backups", 3, "number of backups of the data file to keep")
+	flagLockTimeout := flag.Duration("lock-timeout", 5*time.Second, "wait for other tt processes to release the data file")
+	flagStore := flag.String("store", "json", "format of the data file: 'json', 'journal' or 'sqlite'")
+	flagMonth := flag.Int("month", 0, "output month of the current year (default current)")
 	flagDateFormat := flag.String("date-format", "02.01.2006", "parse and write dates with format")
 	flagTimeFormat := flag.String("time-format", "15:04", "parse and write times with format")
 	flagRoundTo := flag.Int("round-to", 15, "round to minutes")
+	flagProject := flag.String("project", "", "only output intervals of project")
+	flag.Var(&flagTags, "tag", "only output intervals with tag (repeatable)")
+	flagGroupBy := flag.String("group-by", "", "group output by 'project' or 'tag'")
+	flagNoSplit := flag.Bool("no-split", false, "count intervals crossing midnight on their start day")
+	flagFormat := flag.String("format", "text", "output format: 'text', 'json', 'csv', 'markdown', 'html' or 'summary'")
+	flagTemplate := flag.String("template", "", "render the output with a template file, an HTML template if it ends in .html")
+	flagTargets := flag.String("targets", "", "hours to work per weekday, e.g. '8h', '40h/week' or 'mon-thu=8h,fri=6h'")
+	flagBalanceSince := flag.String("balance-since", "", "first date of the overtime balance (default first tracked day)")
+	flagBalanceOpening := flag.Duration("balance-opening", 0, "overtime carried over to the balance")
+	flagVacationDays := flag.Float64("vacation-days", 0, "vacation days per year")
+	flagTZ := flag.String("tz", "", "time zone of input and output, e.g. 'Europe/Berlin' (default local)")
+	flag.String("profile", "", "use the settings of the profile in the config file")
 	flag.Parse()
 
+	configFile := configPath(os.Getenv, home)
+	sources, err := configure(flag.CommandLine, configFile, os.Getenv)
+	exitOnError(err)
+	*flagFile = expandHome(*flagFile, home)
+
+	if flag.Arg(0) == "config" {
+		printConfig(flag.CommandLine, configFile, sources, os.Stdout)
+		return
+	}
+
+	loc := time.Local
+	if *flagTZ != "" {
+		loc, err = time.LoadLocation(*flagTZ)
+		exitOnError(err)
+	}
+
 	var month time.Month
 	if *flagMonth == 0 {
-		month = time.Now().Month()
+		month = time.Now().In(loc).Month()
 	} else {
 		month = time.Month(*flagMonth)
 	}
 
-	timeArg := time.Now()
-	if len(flag.Arg(1)) != 0 {
-		timeArg, err = parseTime(flag.Arg(1), *flagDateFormat, *flagTimeFormat)
-		if err != nil {
-			fmt.Fprintln(os.Stderr, err)
-			os.Exit(1)
-		}
+	groupBy := timesheet.GroupBy(*flagGroupBy)
+	if groupBy != timesheet.GroupByNone && groupBy != timesheet.GroupByProject && groupBy != timesheet.GroupByTag {
+		fmt.Fprintf(os.Stderr, "%s: invalid group-by '%s'\n", os.Args[0], *flagGroupBy)
+		os.Exit(1)
 	}
 
-	file, err := os.OpenFile(*flagFile, os.O_RDWR|os.O_CREATE, 0644)
-	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+	formatter, err := timesheet.NewFormatter(*flagFormat)
+	exitOnError(err)
+	if *flagTemplate != "" {
+		formatter, err = templateFormatter(expandHome(*flagTemplate, home))
+		exitOnError(err)
 	}
-	sheet, err := timesheet.Load(file, *flagDateFormat, *flagTimeFormat)
-	file.Close()
-	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+
+	var targets timesheet.Targets
+	if *flagTargets != "" {
+		targets, err = timesheet.ParseTargets(*flagTargets)
+		exitOnError(err)
+	}
+
+	// commands modify the data file, make sure no other process does the
+	// same in between loading and saving
+	locked := len(flag.Args()) != 0 && !readOnly(flag.Args())
+	if locked {
+		lock, err := timesheet.LockFile(*flagFile, *flagLockTimeout)
+		exitOnError(err)
+		defer lock.Unlock()
+	}
+
+	store, err := openStore(*flagStore, *flagFile, *flagDateFormat, *flagTimeFormat, *flagBackups)
+	exitOnError(err)
+	if f, ok := store.(*timesheet.FileStore); ok {
+		// upgrading writes the file, which is only safe while locked
+		f.Upgrade = locked
+	}
+	if c, ok := store.(io.Closer); ok {
+		defer c.Close()
+	}
+	sheet, err := store.Load()
+	exitOnError(err)
+	// stored times keep their UTC offset, commands read and print times in
+	// the time zone
+	sheet.Location = loc
+	history := timesheet.NewHistory(*flagFile + ".undo")
+
+	opts := timesheet.PrintOptions{
+		RoundTo:   time.Duration(*flagRoundTo) * time.Minute,
+		Filter:    timesheet.Filter{Project: *flagProject, Tags: flagTags},
+		GroupBy:   groupBy,
+		NoSplit:   *flagNoSplit,
+		Targets:   targets,
+		Now:       now(sheet),
+		Formatter: formatter,
 	}
 
 	if len(flag.Args()) != 0 {
+		args := flag.Args()[1:]
+
+		var event timesheet.Event
+		var events []timesheet.Event
 		switch flag.Arg(0) {
 		default:
 			fmt.Fprintf(os.Stderr, "%s: unknown command '%s'\n", os.Args[0], flag.Arg(0))
 			os.Exit(1)
-		case "start":
-			if err := sheet.Start(timeArg); err != nil {
-				fmt.Fprintln(os.Stderr, err)
+		case "migrate":
+			exitOnError(migrate(sheet, args))
+			return
+		case "restore":
+			exitOnError(restore(store, args))
+			return
+		case "compact":
+			exitOnError(compact(store))
+			return
+		case "status":
+			running, err := status(sheet, args, targets, os.Stdout)
+			exitOnError(err)
+			if !running {
 				os.Exit(1)
 			}
+			return
+		case "balance":
+			exitOnError(balance(sheet, args, opts, *flagBalanceSince, *flagBalanceOpening, os.Stdout))
+			return
+		case "report":
+			exitOnError(report(sheet, args, opts, os.Stdout))
+			return
+		case "export":
+			exitOnError(export(sheet, args, opts.Filter, os.Stdout))
+			return
+		case "start":
+			events = append(events, start(sheet, args))
 		case "stop":
-			if err := sheet.End(timeArg); err != nil {
-				fmt.Fprintln(os.Stderr, err)
-				os.Exit(1)
+			events = append(events, stop(sheet, args))
+		case "add":
+			events, err = add(sheet, args)
+		case "edit":
+			events, err = edit(sheet, args)
+		case "amend":
+			event, err = amend(sheet, args)
+			events = append(events, event)
+		case "delete":
+			event, err = remove(sheet, args)
+			events = append(events, event)
+		case "absence":
+			if flag.Arg(1) == "list" {
+				exitOnError(listAbsences(sheet, args[1:], targets, *flagVacationDays, os.Stdout))
+				return
 			}
+			events, err = absence(sheet, args)
+		case "import":
+			events, err = importIntervals(sheet, args, os.Stderr)
+		case "cancel":
+			event, err = cancel(sheet)
+			events = append(events, event)
+		case "undo":
+			events, err = undo(sheet, history)
 		}
+		exitOnError(err)
 
-		file, err = os.Create(*flagFile)
-		if err != nil {
-			fmt.Fprintln(os.Stderr, err)
-			os.Exit(1)
+		// apply to the loaded sheet first to validate all events before
+		// anything is written and include them in the output
+		before := sheet.Intervals()
+		beforeAbsences := sheet.Absences()
+		for _, e := range events {
+			exitOnError(sheet.Apply(e))
 		}
-		defer file.Close()
-		if err := sheet.Save(file); err != nil {
-			fmt.Fprintln(os.Stderr, err)
-			os.Exit(1)
+		// write all changes of the command at once, so a backup never
+		// holds the state in the middle of a command
+		if len(events) > 0 {
+			exitOnError(store.Append(events...))
+		}
+
+		if flag.Arg(0) == "undo" {
+			exitOnError(history.DropLast())
+		} else if len(events) > 0 {
+			change := timesheet.Diff(flag.Arg(0), before, sheet.Intervals())
+			change.DiffAbsences(beforeAbsences, sheet.Absences())
+			exitOnError(history.Push(change))
 		}
 	}
 
-	sheet.PrintMonth(month, time.Duration(*flagRoundTo)*time.Minute, os.Stdout)
+	exitOnError(sheet.PrintMonth(now(sheet).Year(), month, opts, os.Stdout))
 }
 
-func parseTime(value string, dateFormat, timeFormat string) (time.Time, error) {
-	dateTimeFormat := fmt.Sprintf("%s %s", dateFormat, timeFormat)
-	dateTime := fmt.Sprintf("%s %s", time.Now().Format(dateFormat), value)
+func start(sheet *timesheet.Sheet, args []string) timesheet.Event {
+	var tags tagsFlag
+	fs := flag.NewFlagSet("start", flag.ExitOnError)
+	project := fs.String("project", "", "record interval for project")
+	fs.Var(&tags, "tag", "tag interval (repeatable)")
+	note := fs.String("note", "", "note what you are working on")
+	fs.Parse(escapeRelative(args))
+
+	t, rest := timeArg(fs, sheet)
+
+	interval := timesheet.Interval{Start: t, Project: *project, Tags: tags, Note: *note}
+	interval.AddNote(strings.Join(rest, " "))
+
+	return timesheet.Event{Type: timesheet.EventStart, Interval: interval}
+}
+
+func stop(sheet *timesheet.Sheet, args []string) timesheet.Event {
+	fs := flag.NewFlagSet("stop", flag.ExitOnError)
+	note := fs.String("note", "", "note what you worked on")
+	fs.Parse(escapeRelative(args))
+
+	t, rest := timeArg(fs, sheet)
+
+	interval := timesheet.Interval{End: t}
+	interval.AddNote(strings.TrimSpace(*note + " " + strings.Join(rest, " ")))
+
+	return timesheet.Event{Type: timesheet.EventStop, Interval: interval}
+}
+
+// cancel discards the running interval.
+func cancel(sheet *timesheet.Sheet) (timesheet.Event, error) {
+	if _, ok := sheet.OpenInterval(); !ok {
+		return timesheet.Event{}, fmt.Errorf("not started")
+	}
+	return timesheet.Event{Type: timesheet.EventDelete, Index: len(sheet.Intervals()) - 1}, nil
+}
 
-	return time.ParseInLocation(dateTimeFormat, dateTime, time.Now().Location())
+// undo reverts the most recent change.
+func undo(sheet *timesheet.Sheet, history *timesheet.History) ([]timesheet.Event, error) {
+	change, ok, err := history.Last()
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("nothing to undo")
+	}
+	return sheet.Revert(change)
+}
+
+// migrate copies the timesheet into a new data file of the given store.
+func migrate(sheet *timesheet.Sheet, args []string) error {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	to := fs.String("to", "sqlite", "format of the new data file: 'json', 'journal' or 'sqlite'")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return fmt.Errorf("migrate: missing path of new data file")
+	}
+	path := fs.Arg(0)
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("migrate: %s already exists", path)
+	}
+
+	store, err := openStore(*to, path, sheet.DateFormat, sheet.TimeFormat, 0)
+	if err != nil {
+		return err
+	}
+	if c, ok := store.(io.Closer); ok {
+		defer c.Close()
+	}
+
+	return store.Save(sheet)
+}
+
+// restore replaces the data file with one of its backups.
+func restore(store timesheet.Store, args []string) error {
+	r, ok := store.(interface{ Restore(n int) error })
+	if !ok {
+		return fmt.Errorf("restore: not supported by store")
+	}
+
+	n := 1
+	if len(args) > 0 {
+		var err error
+		if n, err = strconv.Atoi(args[0]); err != nil || n < 1 {
+			return fmt.Errorf("restore: invalid backup '%s'", args[0])
+		}
+	}
+
+	return r.Restore(n)
+}
+
+// compact folds the journal into a single snapshot.
+func compact(store timesheet.Store) error {
+	c, ok := store.(interface{ Compact() error })
+	if !ok {
+		return fmt.Errorf("compact: not supported by store")
+	}
+	return c.Compact()
+}
+
+// openStore returns the store of the given kind for the data file.
+func openStore(kind, path, dateFormat, timeFormat string, backups int) (timesheet.Store, error) {
+	switch kind {
+	case "json":
+		store := timesheet.NewFileStore(path, dateFormat, timeFormat)
+		store.Backups = backups
+		return store, nil
+	case "journal":
+		store := timesheet.NewJournalStore(path, dateFormat, timeFormat)
+		store.Backups = backups
+		return store, nil
+	case "sqlite":
+		return sqlite.Open(path, dateFormat, timeFormat)
+	default:
+		return nil, fmt.Errorf("unknown store '%s'", kind)
+	}
+}
+
+// timeArg returns the optional time argument of a command or the current
+// time together with the remaining arguments. Arguments which are no valid
+// time are returned as remaining arguments, like "v2-migration". A time may
+// span two arguments like "yesterday 17:30".
+func timeArg(fs *flag.FlagSet, sheet *timesheet.Sheet) (time.Time, []string) {
+	if fs.NArg() == 0 {
+		return now(sheet), nil
+	}
+	p := parser(sheet)
+	if fs.NArg() > 1 {
+		if t, err := p.Time(fs.Arg(0) + " " + fs.Arg(1)); err == nil {
+			return t, fs.Args()[2:]
+		}
+	}
+	if t, err := p.Time(fs.Arg(0)); err == nil {
+		return t, fs.Args()[1:]
+	}
+	return now(sheet), fs.Args()
+}
+
+// templateFormatter reads a report template. Files ending in .html or .htm
+// are HTML templates.
+func templateFormatter(path string) (timesheet.Formatter, error) {
+	text, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	ext := strings.ToLower(filepath.Ext(path))
+	return timesheet.NewTemplateFormatter(filepath.Base(path), string(text), ext == ".html" || ext == ".htm")
+}
+
+// exitOnError prints the error and exits if err is not nil.
+func exitOnError(err error) {
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// parser returns a parser for the formats and the time zone of the sheet,
+// see package timeparse for the accepted values.
+func parser(sheet *timesheet.Sheet) *timeparse.Parser {
+	return timeparse.New(sheet.DateFormat, sheet.TimeFormat, now(sheet))
+}
+
+// now returns the current time in the time zone of the sheet.
+func now(sheet *timesheet.Sheet) time.Time {
+	return time.Now().In(location(sheet))
+}
+
+// location returns the time zone of the sheet, the local one if it has
+// none.
+func location(sheet *timesheet.Sheet) *time.Location {
+	if sheet.Location == nil {
+		return time.Local
+	}
+	return sheet.Location
+}
+
+// escapeRelative inserts "--" in front of the first argument which is a
+// negative relative time like "-15m", so it is not taken for a flag.
+func escapeRelative(args []string) []string {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if _, err := time.ParseDuration(arg); err == nil && strings.HasPrefix(arg, "-") {
+			escaped := append([]string{}, args[:i]...)
+			escaped = append(escaped, "--")
+			return append(escaped, args[i:]...)
+		}
+	}
+	return args
 }
@@ -1,11 +1,59 @@
 package main
 
 import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"reflect"
+	"strings"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/roccoblues/tt/pkg/timesheet"
 )
 
+// TestMain runs the tt command instead of the tests if TT_TEST_MAIN is set,
+// so tests can start the test binary as tt process. With TT_TEST_EDITOR set
+// to a directory the test binary is an editor instead, see testEditor.
+func TestMain(m *testing.M) {
+	if os.Getenv("TT_TEST_MAIN") != "" {
+		main()
+		os.Exit(0)
+	}
+	if dir := os.Getenv("TT_TEST_EDITOR"); dir != "" && len(os.Args) == 2 {
+		if err := testEditor(dir, os.Args[1]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// testEditor copies the file to before.json in dir and replaces it with
+// after.json from dir if it exists.
+func testEditor(dir, file string) error {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "before.json"), content, 0644); err != nil {
+		return err
+	}
+	after, err := ioutil.ReadFile(filepath.Join(dir, "after.json"))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, after, 0644)
+}
+
 func TestParseTime(t *testing.T) {
 	now := time.Now()
 	timeFormat := "15:04"
@@ -31,14 +79,170 @@ func TestParseTime(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := parseTime(tt.value, dateFormat, timeFormat)
+			got, err := parser(timesheet.NewSheet(dateFormat, timeFormat, nil)).Time(tt.value)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("parseTime() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("parser().Time() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("parseTime() = %v, want %v", got, tt.want)
+				t.Errorf("parser().Time() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
+
+func TestTimeArg(t *testing.T) {
+	now := time.Now()
+	at := func(hour, min int) time.Time {
+		return time.Date(now.Year(), now.Month(), now.Day(), hour, min, 0, 0, now.Location())
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		want     time.Time // Zero for the current time.
+		wantRest []string
+	}{
+		{name: "none"},
+		{name: "time", args: []string{"10:15"}, want: at(10, 15)},
+		{name: "time and note", args: []string{"10:15", "fix", "#123"}, want: at(10, 15), wantRest: []string{"fix", "#123"}},
+		{name: "note", args: []string{"review"}, wantRest: []string{"review"}},
+		{name: "note with digits", args: []string{"v2-migration"}, wantRest: []string{"v2-migration"}},
+		{name: "note with number", args: []string{"fix", "#123"}, wantRest: []string{"fix", "#123"}},
+		{name: "invalid time", args: []string{"25:00"}, wantRest: []string{"25:00"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("start", flag.ContinueOnError)
+			fs.Parse(tt.args)
+			before := time.Now()
+			got, rest := timeArg(fs, timesheet.NewSheet("02.01.2006", "15:04", nil))
+			if tt.want.IsZero() {
+				if got.Before(before.Truncate(time.Minute)) || got.After(time.Now()) {
+					t.Errorf("timeArg() = %v, want current time", got)
+				}
+			} else if !got.Equal(tt.want) {
+				t.Errorf("timeArg() = %v, want %v", got, tt.want)
+			}
+			if strings.Join(rest, " ") != strings.Join(tt.wantRest, " ") {
+				t.Errorf("timeArg() rest = %q, want %q", rest, tt.wantRest)
+			}
+		})
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	tests := []struct {
+		args []string
+		want bool
+	}{
+		{nil, false},
+		{[]string{"start"}, false},
+		{[]string{"report", "-month", "3"}, true},
+		{[]string{"absence", "list"}, true},
+		{[]string{"absence", "add", "vacation", "01.10.2026"}, false},
+		{[]string{"absence"}, false},
+	}
+	for _, tt := range tests {
+		if got := readOnly(tt.args); got != tt.want {
+			t.Errorf("readOnly(%q) = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestConcurrentCommands(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "tt.json")
+	timeFormat := "15:04:05.000000000"
+
+	var mu sync.Mutex
+	succeeded := map[string]int{}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		for _, command := range []string{"start", "stop"} {
+			wg.Add(1)
+			go func(command string) {
+				defer wg.Done()
+
+				cmd := exec.Command(os.Args[0], "-file", file, "-time-format", timeFormat, "-lock-timeout", "30s", command)
+				cmd.Env = append(os.Environ(), "TT_TEST_MAIN=1", "TT_CONFIG="+filepath.Join(dir, "config.json"))
+				// starting while started or stopping while stopped fails
+				if err := cmd.Run(); err != nil {
+					return
+				}
+
+				mu.Lock()
+				succeeded[command]++
+				mu.Unlock()
+			}(command)
+		}
+	}
+	wg.Wait()
+
+	sheet, err := timesheet.NewFileStore(file, "02.01.2006", timeFormat).Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var stopped int
+	for _, i := range sheet.Intervals() {
+		if !i.Open() {
+			stopped++
+		}
+	}
+	if got := len(sheet.Intervals()); got != succeeded["start"] {
+		t.Errorf("data file contains %d intervals, want %d", got, succeeded["start"])
+	}
+	if stopped != succeeded["stop"] {
+		t.Errorf("data file contains %d stopped intervals, want %d", stopped, succeeded["stop"])
+	}
+}
+
+func TestTemplateFormatter(t *testing.T) {
+	loc := time.Now().Location()
+	sheet := timesheet.NewSheet("02.01.2006", "15:04", []timesheet.Interval{
+		{
+			Start:   time.Date(2026, time.March, 2, 9, 0, 0, 0, loc),
+			End:     time.Date(2026, time.March, 2, 12, 30, 0, 0, loc),
+			Project: "acme",
+			Note:    "R&D",
+		},
+		{
+			Start: time.Date(2026, time.March, 3, 14, 0, 0, 0, loc),
+			End:   time.Date(2026, time.March, 3, 15, 0, 0, 0, loc),
+		},
+	})
+
+	formatter, err := templateFormatter("testdata/invoice.html")
+	if err != nil {
+		t.Fatalf("templateFormatter() error = %v", err)
+	}
+
+	var got strings.Builder
+	if err := sheet.PrintMonth(2026, time.March, timesheet.PrintOptions{RoundTo: 15 * time.Minute, Formatter: formatter}, &got); err != nil {
+		t.Fatalf("PrintMonth() error = %v", err)
+	}
+
+	want := `<h1>Timesheet 01.03.2026 - 31.03.2026</h1>
+<table>
+<tr><td>02.03.2026</td><td>09:00-12:30</td><td>3.50</td><td>acme</td><td>R&amp;D</td></tr>
+<tr><td>03.03.2026</td><td>14:00-15:00</td><td>1.00</td><td></td><td></td></tr>
+</table>
+<p>Other: 1.00</p>
+<p>acme: 3.50</p>
+<p>Total: 4.50</p>
+`
+	if got.String() != want {
+		t.Errorf("PrintMonth() = %q, want %q", got.String(), want)
+	}
+
+	if _, err := templateFormatter("testdata/missing.tmpl"); err == nil {
+		t.Errorf("templateFormatter() succeeded for missing file")
+	}
+}
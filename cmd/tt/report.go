@@ -0,0 +1,117 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/roccoblues/tt/pkg/timeparse"
+	"github.com/roccoblues/tt/pkg/timesheet"
+)
+
+// report writes the intervals of a date range.
+func report(sheet *timesheet.Sheet, args []string, opts timesheet.PrintOptions, w io.Writer) error {
+	from, to, err := reportRange(args, parser(sheet))
+	if err != nil {
+		return err
+	}
+	return sheet.PrintRange(from, to, opts, w)
+}
+
+// reportRange returns the range selected by the arguments of the report
+// command. The end of the range is exclusive. Without arguments it is the
+// current month.
+func reportRange(args []string, p *timeparse.Parser) (time.Time, time.Time, error) {
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+	fromDate := fs.String("from", "", "first date of the report")
+	toDate := fs.String("to", "", "last date of the report (default today)")
+	week := fs.Bool("week", false, "report the current week")
+	month := fs.Bool("month", false, "report the current month")
+	year := fs.Bool("year", false, "report the current year")
+	last := fs.String("last", "", "report the last days, weeks, months or years (ie. '4w')")
+	fs.Parse(args)
+
+	if fs.NArg() > 0 {
+		return time.Time{}, time.Time{}, fmt.Errorf("report: unexpected argument '%s'", fs.Arg(0))
+	}
+
+	selected := 0
+	for _, ok := range []bool{*fromDate != "" || *toDate != "", *week, *month, *year, *last != ""} {
+		if ok {
+			selected++
+		}
+	}
+	if selected > 1 {
+		return time.Time{}, time.Time{}, fmt.Errorf("report: only one of -from/-to, -week, -month, -year and -last can be used")
+	}
+
+	today, err := p.Date("today")
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	tomorrow := today.AddDate(0, 0, 1)
+
+	switch {
+	case *fromDate != "" || *toDate != "":
+		if *fromDate == "" {
+			return time.Time{}, time.Time{}, fmt.Errorf("report: -to requires -from")
+		}
+		from, err := p.Date(*fromDate)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		to := tomorrow
+		if *toDate != "" {
+			end, err := p.Date(*toDate)
+			if err != nil {
+				return time.Time{}, time.Time{}, err
+			}
+			if end.Before(from) {
+				return time.Time{}, time.Time{}, fmt.Errorf("report: -to %s is earlier as -from %s", *toDate, *fromDate)
+			}
+			to = end.AddDate(0, 0, 1)
+		}
+		return from, to, nil
+	case *week:
+		// weeks start on monday
+		from := today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+		return from, from.AddDate(0, 0, 7), nil
+	case *year:
+		from := time.Date(today.Year(), time.January, 1, 0, 0, 0, 0, today.Location())
+		return from, from.AddDate(1, 0, 0), nil
+	case *last != "":
+		n, unit, err := parsePeriod(*last)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		switch unit {
+		case 'd':
+			return tomorrow.AddDate(0, 0, -n), tomorrow, nil
+		case 'w':
+			return tomorrow.AddDate(0, 0, -7*n), tomorrow, nil
+		case 'm':
+			return tomorrow.AddDate(0, -n, 0), tomorrow, nil
+		default:
+			return tomorrow.AddDate(-n, 0, 0), tomorrow, nil
+		}
+	default:
+		from := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, today.Location())
+		return from, from.AddDate(0, 1, 0), nil
+	}
+}
+
+// parsePeriod splits a period like "4w" into its count and unit, which is
+// one of 'd', 'w', 'm' or 'y'.
+func parsePeriod(value string) (int, byte, error) {
+	if len(value) < 2 {
+		return 0, 0, fmt.Errorf("invalid period '%s'", value)
+	}
+	unit := value[len(value)-1]
+	n, err := strconv.Atoi(value[:len(value)-1])
+	if err != nil || n < 1 || (unit != 'd' && unit != 'w' && unit != 'm' && unit != 'y') {
+		return 0, 0, fmt.Errorf("invalid period '%s'", value)
+	}
+	return n, unit, nil
+}
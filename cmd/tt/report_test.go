@@ -0,0 +1,119 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/roccoblues/tt/pkg/timeparse"
+)
+
+func TestReportRange(t *testing.T) {
+	loc := time.Now().Location()
+	// a thursday
+	now := time.Date(2026, time.January, 8, 10, 30, 0, 0, loc)
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, loc)
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantFrom time.Time
+		wantTo   time.Time
+		wantErr  bool
+	}{
+		{
+			name:     "default",
+			wantFrom: date(2026, time.January, 1),
+			wantTo:   date(2026, time.February, 1),
+		},
+		{
+			name:     "from to",
+			args:     []string{"-from", "2025-11-01", "-to", "31.01.2026"},
+			wantFrom: date(2025, time.November, 1),
+			wantTo:   date(2026, time.February, 1),
+		},
+		{
+			name:     "from until today",
+			args:     []string{"-from", "2025-12-24"},
+			wantFrom: date(2025, time.December, 24),
+			wantTo:   date(2026, time.January, 9),
+		},
+		{
+			name:     "week across year boundary",
+			args:     []string{"-week"},
+			wantFrom: date(2026, time.January, 5),
+			wantTo:   date(2026, time.January, 12),
+		},
+		{
+			name:     "month",
+			args:     []string{"-month"},
+			wantFrom: date(2026, time.January, 1),
+			wantTo:   date(2026, time.February, 1),
+		},
+		{
+			name:     "year",
+			args:     []string{"-year"},
+			wantFrom: date(2026, time.January, 1),
+			wantTo:   date(2027, time.January, 1),
+		},
+		{
+			name:     "last days",
+			args:     []string{"-last", "3d"},
+			wantFrom: date(2026, time.January, 6),
+			wantTo:   date(2026, time.January, 9),
+		},
+		{
+			name:     "last weeks",
+			args:     []string{"-last", "4w"},
+			wantFrom: date(2025, time.December, 12),
+			wantTo:   date(2026, time.January, 9),
+		},
+		{
+			name:     "last months",
+			args:     []string{"-last", "2m"},
+			wantFrom: date(2025, time.November, 9),
+			wantTo:   date(2026, time.January, 9),
+		},
+		{
+			name:     "last years",
+			args:     []string{"-last", "1y"},
+			wantFrom: date(2025, time.January, 9),
+			wantTo:   date(2026, time.January, 9),
+		},
+		{
+			name:    "invalid period",
+			args:    []string{"-last", "4x"},
+			wantErr: true,
+		},
+		{
+			name:    "to before from",
+			args:    []string{"-from", "2026-01-08", "-to", "2026-01-07"},
+			wantErr: true,
+		},
+		{
+			name:    "to without from",
+			args:    []string{"-to", "2026-01-07"},
+			wantErr: true,
+		},
+		{
+			name:    "conflicting options",
+			args:    []string{"-week", "-year"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to, err := reportRange(tt.args, timeparse.New("02.01.2006", "15:04", now))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("reportRange() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !from.Equal(tt.wantFrom) || !to.Equal(tt.wantTo) {
+				t.Errorf("reportRange() = %v - %v, want %v - %v", from, to, tt.wantFrom, tt.wantTo)
+			}
+		})
+	}
+}
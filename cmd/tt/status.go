@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/roccoblues/tt/pkg/timesheet"
+)
+
+// statusJSON is the output of "status -format json". Durations are in
+// seconds.
+type statusJSON struct {
+	Running   bool       `json:"running"`
+	Start     *time.Time `json:"start,omitempty"`
+	Project   string     `json:"project,omitempty"`
+	Tags      []string   `json:"tags,omitempty"`
+	Note      string     `json:"note,omitempty"`
+	Elapsed   int64      `json:"elapsed"`
+	Today     int64      `json:"today"`
+	Target    int64      `json:"target"`
+	Remaining int64      `json:"remaining"`
+}
+
+// status writes whether an interval is running, for how long and the time
+// tracked today. With targets it also writes the time remaining of the
+// target of today, absences are credited like in the balance. It reports
+// whether an interval is running.
+func status(sheet *timesheet.Sheet, args []string, targets timesheet.Targets, w io.Writer) (bool, error) {
+	fs := flag.NewFlagSet("status", flag.ExitOnError)
+	format := fs.String("format", "text", "output format: 'text' or 'json'")
+	fs.Parse(args)
+
+	today, err := parser(sheet).Date("today")
+	if err != nil {
+		return false, err
+	}
+	s := sheet.Status(now(sheet))
+
+	b := sheet.Balance(today, today.AddDate(0, 0, 1), targets, 0)
+	target := b.Target - b.Credit
+	remaining := target - s.Today
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	switch *format {
+	case "text":
+		if s.Running {
+			fmt.Fprintf(w, "Running since %s (%s)", s.Interval.Start.Format(sheet.TimeFormat), formatDuration(s.Elapsed))
+			if s.Interval.Project != "" {
+				fmt.Fprintf(w, " on %s", s.Interval.Project)
+			}
+			if len(s.Interval.Tags) > 0 {
+				fmt.Fprintf(w, " [%s]", strings.Join(s.Interval.Tags, ", "))
+			}
+			if s.Interval.Note != "" {
+				fmt.Fprintf(w, ": %s", s.Interval.Note)
+			}
+			fmt.Fprintln(w, "")
+		} else {
+			fmt.Fprintln(w, "Not running")
+		}
+		fmt.Fprintf(w, "Today: %s", formatDuration(s.Today))
+		if targets != nil {
+			fmt.Fprintf(w, ", %s remaining", formatDuration(remaining))
+		}
+		fmt.Fprintln(w, "")
+	case "json":
+		out := statusJSON{
+			Running:   s.Running,
+			Elapsed:   int64(s.Elapsed / time.Second),
+			Today:     int64(s.Today / time.Second),
+			Target:    int64(target / time.Second),
+			Remaining: int64(remaining / time.Second),
+		}
+		if s.Running {
+			out.Start = &s.Interval.Start
+			out.Project = s.Interval.Project
+			out.Tags = s.Interval.Tags
+			out.Note = s.Interval.Note
+		}
+		if err := json.NewEncoder(w).Encode(out); err != nil {
+			return false, err
+		}
+	default:
+		return false, fmt.Errorf("status: unknown format '%s'", *format)
+	}
+
+	return s.Running, nil
+}
+
+// formatDuration formats the duration as hours and minutes (ie. "1:05").
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	return fmt.Sprintf("%d:%02d", d/time.Hour, (d%time.Hour)/time.Minute)
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/roccoblues/tt/pkg/timesheet"
+)
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		value time.Duration
+		want  string
+	}{
+		{value: 0, want: "0:00"},
+		{value: 5 * time.Minute, want: "0:05"},
+		{value: 90*time.Minute + 40*time.Second, want: "1:31"},
+		{value: 26 * time.Hour, want: "26:00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := formatDuration(tt.value); got != tt.want {
+				t.Errorf("formatDuration() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatus(t *testing.T) {
+	year, month, day := time.Now().Date()
+	today := time.Date(year, month, day, 0, 0, 0, 0, time.Now().Location())
+
+	everyDay, err := timesheet.ParseTargets("sun-sat=8h")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		targets  timesheet.Targets
+		absences []timesheet.Absence
+		want     string
+	}{
+		{
+			name: "no targets",
+			want: "Not running\nToday: 2:00\n",
+		},
+		{
+			name:    "target of today",
+			targets: everyDay,
+			want:    "Not running\nToday: 2:00, 6:00 remaining\n",
+		},
+		{
+			name:     "half vacation",
+			targets:  everyDay,
+			absences: []timesheet.Absence{{Date: today, Type: timesheet.AbsenceVacation, Half: true}},
+			want:     "Not running\nToday: 2:00, 2:00 remaining\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sheet := timesheet.NewSheet("02.01.2006", "15:04", []timesheet.Interval{
+				{Start: today.Add(time.Hour), End: today.Add(3 * time.Hour)},
+			})
+			for _, a := range tt.absences {
+				if err := sheet.AddAbsence(a); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var got bytes.Buffer
+			running, err := status(sheet, nil, tt.targets, &got)
+			if err != nil {
+				t.Fatalf("status() error = %v", err)
+			}
+			if running {
+				t.Errorf("status() running = true, want false")
+			}
+			if got.String() != tt.want {
+				t.Errorf("status() = %q, want %q", got.String(), tt.want)
+			}
+		})
+	}
+}
@@ -2,4 +2,7 @@ module github.com/roccoblues/tt
 
 go 1.12
 
-require github.com/google/go-cmp v0.3.1
+require (
+	github.com/google/go-cmp v0.3.1
+	github.com/mattn/go-sqlite3 v1.11.0
+)
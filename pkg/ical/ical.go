@@ -0,0 +1,320 @@
+// Package ical reads and writes iCalendar (RFC 5545) events.
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Event is a VEVENT of a calendar.
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Categories  []string
+	Start       time.Time
+	End         time.Time // Exclusive, zero if the event has no end.
+	AllDay      bool      // Start and end are dates without time.
+	Stamp       time.Time // When the event was written, the current time if zero.
+}
+
+// HasCategory reports whether the event is in the category. Categories are
+// compared case-insensitively.
+func (e Event) HasCategory(category string) bool {
+	for _, c := range e.Categories {
+		if strings.EqualFold(c, category) {
+			return true
+		}
+	}
+	return false
+}
+
+// property is a content line like "DTSTART;VALUE=DATE:20261225".
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// Parse reads all events of the calendar. Times keep their time zone, UTC
+// times are in UTC and times without UTC offset or known time zone in the
+// local time zone. Dates are at local midnight.
+func Parse(r io.Reader) ([]Event, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []Event
+	var event *Event
+	nested := 0 // depth of components inside the event, like VALARM
+	for n, line := range lines {
+		if line == "" {
+			continue
+		}
+		p, err := parseProperty(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", n+1, err)
+		}
+
+		switch {
+		case p.name == "BEGIN" && strings.EqualFold(p.value, "VEVENT"):
+			event = &Event{}
+		case p.name == "END" && strings.EqualFold(p.value, "VEVENT"):
+			if event == nil {
+				return nil, fmt.Errorf("line %d: END:VEVENT without BEGIN", n+1)
+			}
+			if event.Start.IsZero() {
+				return nil, fmt.Errorf("line %d: event without DTSTART", n+1)
+			}
+			events = append(events, *event)
+			event = nil
+		case event == nil:
+		case p.name == "BEGIN":
+			nested++
+		case p.name == "END" && nested > 0:
+			nested--
+		case nested > 0:
+		case p.name == "UID":
+			event.UID = p.value
+		case p.name == "SUMMARY":
+			event.Summary = unescape(p.value)
+		case p.name == "DESCRIPTION":
+			event.Description = unescape(p.value)
+		case p.name == "CATEGORIES":
+			for _, c := range splitText(p.value) {
+				event.Categories = append(event.Categories, unescape(c))
+			}
+		case p.name == "DTSTART":
+			if event.Start, event.AllDay, err = parseTime(p); err != nil {
+				return nil, fmt.Errorf("line %d: %s", n+1, err)
+			}
+		case p.name == "DTEND":
+			if event.End, _, err = parseTime(p); err != nil {
+				return nil, fmt.Errorf("line %d: %s", n+1, err)
+			}
+		case p.name == "DTSTAMP":
+			if event.Stamp, _, err = parseTime(p); err != nil {
+				return nil, fmt.Errorf("line %d: %s", n+1, err)
+			}
+		}
+	}
+	if event != nil {
+		return nil, fmt.Errorf("missing END:VEVENT")
+	}
+
+	return events, nil
+}
+
+// unfold returns the content lines with continuation lines joined.
+func unfold(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// parseProperty splits a content line into name, parameters and value.
+// Parameter values may be quoted and contain ";" and ":".
+func parseProperty(line string) (property, error) {
+	p := property{params: map[string]string{}}
+
+	end := strings.IndexAny(line, ";:")
+	if end < 0 {
+		return p, fmt.Errorf("invalid content line '%s'", line)
+	}
+	p.name = strings.ToUpper(line[:end])
+
+	rest := line[end:]
+	for strings.HasPrefix(rest, ";") {
+		rest = rest[1:]
+		eq := strings.Index(rest, "=")
+		if eq < 0 {
+			return p, fmt.Errorf("invalid parameter in '%s'", line)
+		}
+		name := strings.ToUpper(rest[:eq])
+		rest = rest[eq+1:]
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			q := strings.Index(rest[1:], `"`)
+			if q < 0 {
+				return p, fmt.Errorf("unterminated quote in '%s'", line)
+			}
+			value, rest = rest[1:q+1], rest[q+2:]
+		} else {
+			n := strings.IndexAny(rest, ";:")
+			if n < 0 {
+				return p, fmt.Errorf("invalid content line '%s'", line)
+			}
+			value, rest = rest[:n], rest[n:]
+		}
+		p.params[name] = value
+	}
+
+	if !strings.HasPrefix(rest, ":") {
+		return p, fmt.Errorf("invalid content line '%s'", line)
+	}
+	p.value = rest[1:]
+
+	return p, nil
+}
+
+// parseTime parses a DATE or DATE-TIME value and reports whether it is a
+// date. UTC times stay in UTC and times with TZID in that time zone.
+func parseTime(p property) (time.Time, bool, error) {
+	if p.params["VALUE"] == "DATE" || len(p.value) == len("20060102") {
+		t, err := time.ParseInLocation("20060102", p.value, time.Now().Location())
+		return t, true, err
+	}
+	if strings.HasSuffix(p.value, "Z") {
+		t, err := time.Parse("20060102T150405Z", p.value)
+		return t, false, err
+	}
+
+	loc := time.Now().Location()
+	if tzid, ok := p.params["TZID"]; ok {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	t, err := time.ParseInLocation("20060102T150405", p.value, loc)
+	return t, false, err
+}
+
+// splitText splits a list of text values at unescaped commas.
+func splitText(value string) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			i++
+		case ',':
+			parts = append(parts, value[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, value[start:])
+}
+
+// unescape replaces the escape sequences of text values.
+func unescape(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' && i+1 < len(value) {
+			i++
+			switch value[i] {
+			case 'n', 'N':
+				b.WriteByte('\n')
+			default:
+				b.WriteByte(value[i])
+			}
+			continue
+		}
+		b.WriteByte(value[i])
+	}
+	return b.String()
+}
+
+// prodID identifies tt as the product which wrote a calendar.
+const prodID = "-//roccoblues//tt//EN"
+
+// maxLine is the maximum length of a content line in octets, longer lines
+// are folded.
+const maxLine = 75
+
+// Write writes a calendar with the events. Times are written in UTC, dates
+// of all-day events without time zone.
+func Write(w io.Writer, events []Event) error {
+	var b strings.Builder
+	line := func(name, value string) {
+		b.WriteString(fold(name + ":" + value))
+		b.WriteString("\r\n")
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", prodID)
+	for _, e := range events {
+		stamp := e.Stamp
+		if stamp.IsZero() {
+			stamp = time.Now()
+		}
+
+		line("BEGIN", "VEVENT")
+		line("UID", e.UID)
+		line("DTSTAMP", formatTime(stamp))
+		if e.AllDay {
+			line("DTSTART;VALUE=DATE", e.Start.Format("20060102"))
+			if !e.End.IsZero() {
+				line("DTEND;VALUE=DATE", e.End.Format("20060102"))
+			}
+		} else {
+			line("DTSTART", formatTime(e.Start))
+			if !e.End.IsZero() {
+				line("DTEND", formatTime(e.End))
+			}
+		}
+		if e.Summary != "" {
+			line("SUMMARY", escape(e.Summary))
+		}
+		if e.Description != "" {
+			line("DESCRIPTION", escape(e.Description))
+		}
+		if len(e.Categories) > 0 {
+			categories := make([]string, len(e.Categories))
+			for n, c := range e.Categories {
+				categories[n] = escape(c)
+			}
+			line("CATEGORIES", strings.Join(categories, ","))
+		}
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// formatTime returns t as UTC DATE-TIME value.
+func formatTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// escape escapes the special characters of text values.
+func escape(value string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(value)
+}
+
+// fold splits lines longer than maxLine octets into continuation lines
+// without splitting characters.
+func fold(line string) string {
+	var b strings.Builder
+	limit := maxLine
+	for len(line) > limit {
+		n := limit
+		for n > 0 && !utf8.RuneStart(line[n]) {
+			n--
+		}
+		b.WriteString(line[:n])
+		b.WriteString("\r\n ")
+		line = line[n:]
+		// the leading space of continuation lines counts as well
+		limit = maxLine - 1
+	}
+	b.WriteString(line)
+	return b.String()
+}
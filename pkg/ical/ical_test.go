@@ -0,0 +1,188 @@
+package ical
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParse(t *testing.T) {
+	file, err := os.Open("testdata/holidays.ics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		// unknown time zones fall back to the local time zone
+		berlin = time.Now().Location()
+	}
+	loc := time.Now().Location()
+
+	want := []Event{
+		{
+			UID:        "2026-12-25@holidays.example",
+			Summary:    "Christmas",
+			Categories: []string{"Holidays", "Public"},
+			Start:      time.Date(2026, time.December, 25, 0, 0, 0, 0, loc),
+			End:        time.Date(2026, time.December, 27, 0, 0, 0, 0, loc),
+			AllDay:     true,
+		},
+		{
+			UID:         "2026-12-31@holidays.example",
+			Summary:     "New Year, Eve",
+			Description: "Half day in most offices\nCheck your contract",
+			Categories:  []string{"Observance"},
+			Start:       time.Date(2026, time.December, 31, 0, 0, 0, 0, loc),
+			AllDay:      true,
+		},
+		{
+			UID:     "meeting-1",
+			Summary: "Planning",
+			Start:   time.Date(2026, time.January, 5, 9, 0, 0, 0, berlin),
+			End:     time.Date(2026, time.January, 5, 10, 0, 0, 0, time.UTC),
+		},
+	}
+
+	got, err := Parse(file)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Parse() differs: (-want +got)\n%s", diff)
+	}
+
+	if !got[0].HasCategory("holidays") || got[0].HasCategory("observance") {
+		t.Errorf("HasCategory() doesn't match categories %v", got[0].Categories)
+	}
+}
+
+func TestParseTimeZones(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone database not available: %s", err)
+	}
+
+	data := "BEGIN:VEVENT\r\n" +
+		"DTSTART;TZID=America/New_York:20260105T090000\r\n" +
+		"DTEND:20260105T150000Z\r\n" +
+		"DTSTAMP:20260101T120000\r\n" +
+		"END:VEVENT\r\n"
+	events, err := Parse(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	e := events[0]
+	if e.Start.Location().String() != newYork.String() || e.Start.Hour() != 9 {
+		t.Errorf("Parse() start = %v, want 09:00 in %v", e.Start, newYork)
+	}
+	if e.End.Location() != time.UTC || e.End.Hour() != 15 {
+		t.Errorf("Parse() end = %v, want 15:00 UTC", e.End)
+	}
+	if e.Stamp.Location() != time.Local || e.Stamp.Hour() != 12 {
+		t.Errorf("Parse() stamp = %v, want 12:00 local", e.Stamp)
+	}
+}
+
+func TestParseAlarm(t *testing.T) {
+	data := "BEGIN:VEVENT\r\n" +
+		"SUMMARY:Standup\r\n" +
+		"DTSTART:20260302T070000Z\r\n" +
+		"BEGIN:VALARM\r\n" +
+		"ACTION:DISPLAY\r\n" +
+		"SUMMARY:Reminder\r\n" +
+		"DESCRIPTION:Standup in 15 minutes\r\n" +
+		"DTSTART:20260302T064500Z\r\n" +
+		"END:VALARM\r\n" +
+		"DTEND:20260302T073000Z\r\n" +
+		"END:VEVENT\r\n"
+	events, err := Parse(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	want := []Event{{
+		Summary: "Standup",
+		Start:   time.Date(2026, time.March, 2, 7, 0, 0, 0, time.UTC),
+		End:     time.Date(2026, time.March, 2, 7, 30, 0, 0, time.UTC),
+	}}
+	if diff := cmp.Diff(want, events); diff != "" {
+		t.Errorf("Parse() differs: (-want +got)\n%s", diff)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "missing end", data: "BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART:20260105T090000Z\n"},
+		{name: "missing start", data: "BEGIN:VEVENT\nSUMMARY:x\nEND:VEVENT\n"},
+		{name: "invalid date", data: "BEGIN:VEVENT\nDTSTART;VALUE=DATE:2026-01-05\nEND:VEVENT\n"},
+		{name: "invalid line", data: "BEGIN:VEVENT\nDTSTART\nEND:VEVENT\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(strings.NewReader(tt.data)); err == nil {
+				t.Errorf("Parse() succeeded")
+			}
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	// times are written in UTC, dates in the local time zone
+	cest := time.FixedZone("CEST", 2*60*60)
+	loc := time.Now().Location()
+	events := []Event{
+		{
+			UID:        "20260105T070000Z@tt",
+			Summary:    "acme: fixing invoices, reminders; and a long note which doesn't fit on a single line — äöü",
+			Categories: []string{"billable", "a,b"},
+			Start:      time.Date(2026, time.January, 5, 9, 0, 0, 0, cest),
+			End:        time.Date(2026, time.January, 5, 12, 30, 0, 0, cest),
+			Stamp:      time.Date(2026, time.January, 6, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			UID:         "2026-12-25@holidays.example",
+			Summary:     "Christmas",
+			Description: "first line\nsecond line",
+			Start:       time.Date(2026, time.December, 25, 0, 0, 0, 0, loc),
+			End:         time.Date(2026, time.December, 27, 0, 0, 0, 0, loc),
+			AllDay:      true,
+			Stamp:       time.Date(2026, time.January, 6, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	var got strings.Builder
+	if err := Write(&got, events); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	want, err := ioutil.ReadFile("testdata/tt.ics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(string(want), got.String()); diff != "" {
+		t.Errorf("Write() differs: (-want +got)\n%s", diff)
+	}
+	for _, line := range strings.Split(got.String(), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("Write() line %q longer than 75 octets", line)
+		}
+	}
+
+	parsed, err := Parse(strings.NewReader(got.String()))
+	if err != nil {
+		t.Fatalf("Parse(Write()) error = %v", err)
+	}
+	if diff := cmp.Diff(events, parsed); diff != "" {
+		t.Errorf("Parse(Write()) differs: (-want +got)\n%s", diff)
+	}
+}
@@ -0,0 +1,413 @@
+// Package sqlite stores timesheets in an SQLite database.
+package sqlite
+
+import (
+	"database/sql"
+	"time"
+
+	// register the sqlite3 driver
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/roccoblues/tt/pkg/timesheet"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS projects (
+	id INTEGER PRIMARY KEY,
+	name TEXT NOT NULL UNIQUE
+);
+CREATE TABLE IF NOT EXISTS intervals (
+	id INTEGER PRIMARY KEY,
+	start_time INTEGER NOT NULL,
+	start_offset INTEGER,
+	end_time INTEGER,
+	end_offset INTEGER,
+	project_id INTEGER REFERENCES projects(id)
+);
+CREATE INDEX IF NOT EXISTS intervals_start ON intervals(start_time);
+CREATE TABLE IF NOT EXISTS tags (
+	interval_id INTEGER NOT NULL REFERENCES intervals(id) ON DELETE CASCADE,
+	position INTEGER NOT NULL,
+	name TEXT NOT NULL,
+	PRIMARY KEY (interval_id, position)
+);
+CREATE INDEX IF NOT EXISTS tags_name ON tags(name);
+CREATE TABLE IF NOT EXISTS notes (
+	interval_id INTEGER PRIMARY KEY REFERENCES intervals(id) ON DELETE CASCADE,
+	note TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS uids (
+	interval_id INTEGER PRIMARY KEY REFERENCES intervals(id) ON DELETE CASCADE,
+	uid TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS absences (
+	id INTEGER PRIMARY KEY,
+	date TEXT NOT NULL,
+	type TEXT NOT NULL,
+	half INTEGER NOT NULL DEFAULT 0,
+	note TEXT NOT NULL DEFAULT ''
+);
+`
+
+// Store keeps the timesheet in an SQLite database. Times are stored as
+// nanoseconds since the Unix epoch together with their UTC offset in
+// seconds, the dates of absences as "2006-01-02".
+type Store struct {
+	DateFormat string // Format used to write and parse dates.
+	TimeFormat string // Format used to write and parse times.
+	db         *sql.DB
+}
+
+// Open opens the database at path and creates the tables if necessary.
+func Open(path, dateFormat, timeFormat string) (*Store, error) {
+	db, err := sql.Open("sqlite3", path+"?_foreign_keys=1")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, err
+	}
+	// databases of older releases have no UTC offsets, their times are
+	// read as local times
+	if _, err := db.Exec("SELECT start_offset FROM intervals LIMIT 0"); err != nil {
+		if _, err := db.Exec("ALTER TABLE intervals ADD COLUMN start_offset INTEGER; ALTER TABLE intervals ADD COLUMN end_offset INTEGER"); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+
+	store := &Store{
+		DateFormat: dateFormat,
+		TimeFormat: timeFormat,
+		db:         db,
+	}
+
+	return store, nil
+}
+
+// Close closes the database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Load reads the complete timesheet.
+func (s *Store) Load() (*timesheet.Sheet, error) {
+	intervals, err := s.query(s.db, "")
+	if err != nil {
+		return nil, err
+	}
+	sheet := timesheet.NewSheet(s.DateFormat, s.TimeFormat, intervals)
+
+	absences, err := queryAbsences(s.db)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range absences {
+		if err := sheet.AddAbsence(a); err != nil {
+			return nil, err
+		}
+	}
+
+	return sheet, nil
+}
+
+// Save replaces all stored intervals and absences with those of the sheet.
+func (s *Store) Save(sheet *timesheet.Sheet) error {
+	return s.transaction(func(tx *sql.Tx) error {
+		for _, table := range []string{"notes", "tags", "intervals", "projects", "absences"} {
+			if _, err := tx.Exec("DELETE FROM " + table); err != nil {
+				return err
+			}
+		}
+		for _, i := range sheet.Intervals() {
+			if err := insert(tx, i); err != nil {
+				return err
+			}
+		}
+		for _, a := range sheet.Absences() {
+			if _, err := tx.Exec("INSERT INTO absences (date, type, half, note) VALUES (?, ?, ?, ?)", a.Date.Format(dateLayout), string(a.Type), a.Half, a.Note); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Append records the events in one transaction. A single start or stop
+// event only touches the affected interval, all other changes rewrite the
+// timesheet once.
+func (s *Store) Append(events ...timesheet.Event) error {
+	if len(events) != 1 || (events[0].Type != timesheet.EventStart && events[0].Type != timesheet.EventStop) {
+		sheet, err := s.Load()
+		if err != nil {
+			return err
+		}
+		for _, e := range events {
+			if err := sheet.Apply(e); err != nil {
+				return err
+			}
+		}
+		return s.Save(sheet)
+	}
+	e := events[0]
+
+	return s.transaction(func(tx *sql.Tx) error {
+		// Starting and stopping only depends on the intervals of the day
+		// and the most recent interval.
+		t := e.Interval.Start
+		if e.Type == timesheet.EventStop {
+			t = e.Interval.End
+		}
+		year, month, day := t.Date()
+		since := time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+
+		intervals, err := s.query(tx, "WHERE i.start_time >= ? OR i.id = (SELECT id FROM intervals ORDER BY start_time DESC LIMIT 1)", since.UnixNano())
+		if err != nil {
+			return err
+		}
+		sheet := timesheet.NewSheet(s.DateFormat, s.TimeFormat, intervals)
+		if err := sheet.Apply(e); err != nil {
+			return err
+		}
+
+		if e.Type == timesheet.EventStart {
+			interval := e.Interval
+			interval.End = time.Time{}
+			return insert(tx, interval)
+		}
+		intervals = sheet.Intervals()
+		return update(tx, intervals[len(intervals)-1])
+	})
+}
+
+// queryer is implemented by sql.DB and sql.Tx.
+type queryer interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// query returns the intervals matching the where clause ordered by start.
+func (s *Store) query(q queryer, where string, args ...interface{}) ([]timesheet.Interval, error) {
+	rows, err := q.Query(`
+		SELECT i.id, i.start_time, i.start_offset, i.end_time, i.end_offset, p.name, n.note, u.uid
+		FROM intervals i
+		LEFT JOIN projects p ON p.id = i.project_id
+		LEFT JOIN notes n ON n.interval_id = i.id
+		LEFT JOIN uids u ON u.interval_id = i.id
+		`+where+`
+		ORDER BY i.start_time`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	var intervals []timesheet.Interval
+	for rows.Next() {
+		var id, start int64
+		var startOffset, end, endOffset sql.NullInt64
+		var project, note, uid sql.NullString
+		if err := rows.Scan(&id, &start, &startOffset, &end, &endOffset, &project, &note, &uid); err != nil {
+			return nil, err
+		}
+
+		interval := timesheet.Interval{
+			Start:   fromUnixNano(start, startOffset),
+			Project: project.String,
+			Note:    note.String,
+			UID:     uid.String,
+		}
+		if end.Valid {
+			interval.End = fromUnixNano(end.Int64, endOffset)
+		}
+
+		ids = append(ids, id)
+		intervals = append(intervals, interval)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	tags, err := queryTags(q, where, args...)
+	if err != nil {
+		return nil, err
+	}
+	for n, id := range ids {
+		intervals[n].Tags = tags[id]
+	}
+
+	return intervals, nil
+}
+
+// queryTags returns the tags of the intervals matching the where clause
+// by interval id.
+func queryTags(q queryer, where string, args ...interface{}) (map[int64][]string, error) {
+	rows, err := q.Query(`
+		SELECT t.interval_id, t.name
+		FROM tags t
+		JOIN intervals i ON i.id = t.interval_id
+		`+where+`
+		ORDER BY t.interval_id, t.position`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := map[int64][]string{}
+	for rows.Next() {
+		var id int64
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, err
+		}
+		tags[id] = append(tags[id], name)
+	}
+
+	return tags, rows.Err()
+}
+
+// dateLayout is the format of the dates of absences.
+const dateLayout = "2006-01-02"
+
+// queryAbsences returns all absences ordered by date.
+func queryAbsences(q queryer) ([]timesheet.Absence, error) {
+	rows, err := q.Query("SELECT date, type, half, note FROM absences ORDER BY date, id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var absences []timesheet.Absence
+	for rows.Next() {
+		var date, kind string
+		var a timesheet.Absence
+		if err := rows.Scan(&date, &kind, &a.Half, &a.Note); err != nil {
+			return nil, err
+		}
+		if a.Date, err = time.Parse(dateLayout, date); err != nil {
+			return nil, err
+		}
+		a.Type = timesheet.AbsenceType(kind)
+		absences = append(absences, a)
+	}
+
+	return absences, rows.Err()
+}
+
+// insert adds the interval with its project, tags, note and UID.
+func insert(tx *sql.Tx, i timesheet.Interval) error {
+	projectID, err := projectID(tx, i.Project)
+	if err != nil {
+		return err
+	}
+
+	res, err := tx.Exec("INSERT INTO intervals (start_time, start_offset, end_time, end_offset, project_id) VALUES (?, ?, ?, ?, ?)", i.Start.UnixNano(), offset(i.Start), unixNano(i.End), offset(i.End), projectID)
+	if err != nil {
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	for position, tag := range i.Tags {
+		if _, err := tx.Exec("INSERT INTO tags (interval_id, position, name) VALUES (?, ?, ?)", id, position, tag); err != nil {
+			return err
+		}
+	}
+	if i.UID != "" {
+		if _, err := tx.Exec("INSERT INTO uids (interval_id, uid) VALUES (?, ?)", id, i.UID); err != nil {
+			return err
+		}
+	}
+
+	return setNote(tx, id, i.Note)
+}
+
+// update writes end time and note of the interval with the same start
+// time.
+func update(tx *sql.Tx, i timesheet.Interval) error {
+	var id int64
+	err := tx.QueryRow("SELECT id FROM intervals WHERE start_time = ?", i.Start.UnixNano()).Scan(&id)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("UPDATE intervals SET end_time = ?, end_offset = ? WHERE id = ?", unixNano(i.End), offset(i.End), id); err != nil {
+		return err
+	}
+
+	return setNote(tx, id, i.Note)
+}
+
+func setNote(tx *sql.Tx, id int64, note string) error {
+	if note == "" {
+		_, err := tx.Exec("DELETE FROM notes WHERE interval_id = ?", id)
+		return err
+	}
+	_, err := tx.Exec("INSERT OR REPLACE INTO notes (interval_id, note) VALUES (?, ?)", id, note)
+	return err
+}
+
+// projectID returns the id of the named project and creates it if
+// necessary. The empty project has no id.
+func projectID(tx *sql.Tx, name string) (sql.NullInt64, error) {
+	if name == "" {
+		return sql.NullInt64{}, nil
+	}
+
+	if _, err := tx.Exec("INSERT OR IGNORE INTO projects (name) VALUES (?)", name); err != nil {
+		return sql.NullInt64{}, err
+	}
+
+	var id int64
+	if err := tx.QueryRow("SELECT id FROM projects WHERE name = ?", name).Scan(&id); err != nil {
+		return sql.NullInt64{}, err
+	}
+
+	return sql.NullInt64{Int64: id, Valid: true}, nil
+}
+
+func (s *Store) transaction(fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// unixNano returns the time as nanoseconds or NULL for the zero time.
+func unixNano(t time.Time) sql.NullInt64 {
+	if t.IsZero() {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: t.UnixNano(), Valid: true}
+}
+
+// offset returns the UTC offset of the time in seconds or NULL for the zero
+// time.
+func offset(t time.Time) sql.NullInt64 {
+	if t.IsZero() {
+		return sql.NullInt64{}
+	}
+	_, seconds := t.Zone()
+	return sql.NullInt64{Int64: int64(seconds), Valid: true}
+}
+
+// fromUnixNano returns the time with the given UTC offset. Like times
+// parsed with an offset it is local if the local time zone has the same
+// offset at that time. Times without offset are local.
+func fromUnixNano(n int64, offset sql.NullInt64) time.Time {
+	t := time.Unix(0, n)
+	if !offset.Valid {
+		return t
+	}
+	if _, local := t.Zone(); local == int(offset.Int64) {
+		return t
+	}
+	return t.In(time.FixedZone("", int(offset.Int64)))
+}
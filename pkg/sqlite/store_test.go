@@ -0,0 +1,178 @@
+package sqlite
+
+import (
+	"bytes"
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/roccoblues/tt/pkg/timesheet"
+)
+
+var (
+	dateFormat = "02.01.2006"
+	timeFormat = "15:04"
+)
+
+// TestMain runs the tests in a fixed time zone, so the UTC offsets in the
+// fixtures don't depend on the machine.
+func TestMain(m *testing.M) {
+	time.Local = time.FixedZone("CEST", 2*60*60)
+	os.Exit(m.Run())
+}
+
+func openTestStore(t *testing.T) (*Store, func()) {
+	dir, err := ioutil.TempDir("", "tt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := Open(filepath.Join(dir, "tt.db"), dateFormat, timeFormat)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+
+	return store, func() {
+		store.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	store, cleanup := openTestStore(t)
+	defer cleanup()
+
+	want, err := ioutil.ReadFile("testdata/tt.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sheet, err := timesheet.Load(bytes.NewReader(want), dateFormat, timeFormat)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// save twice to make sure the old data is replaced
+	for i := 0; i < 2; i++ {
+		if err := store.Save(sheet); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	loaded, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	var got bytes.Buffer
+	if err := loaded.Save(&got); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(string(want), got.String()); diff != "" {
+		t.Errorf("Load(Save()) differs: (-want +got)\n%s", diff)
+	}
+}
+
+func TestAppend(t *testing.T) {
+	store, cleanup := openTestStore(t)
+	defer cleanup()
+
+	first := timesheet.Interval{
+		Start:   time.Date(2018, time.September, 1, 8, 0, 0, 0, time.Now().Location()),
+		End:     time.Date(2018, time.September, 1, 12, 0, 0, 0, time.Now().Location()),
+		Project: "acme",
+		Tags:    []string{"review"},
+	}
+	second := timesheet.Interval{
+		Start: time.Date(2018, time.September, 2, 22, 0, 0, 0, time.Now().Location()),
+		End:   time.Date(2018, time.September, 3, 2, 0, 0, 0, time.Now().Location()),
+		Note:  "night shift; done",
+	}
+
+	events := []timesheet.Event{
+		{Type: timesheet.EventStart, Interval: timesheet.Interval{Start: first.Start, Project: first.Project, Tags: first.Tags}},
+		{Type: timesheet.EventStop, Interval: timesheet.Interval{End: first.End}},
+		{Type: timesheet.EventStart, Interval: timesheet.Interval{Start: second.Start, Note: "night shift"}},
+		{Type: timesheet.EventStop, Interval: timesheet.Interval{End: second.End, Note: "done"}},
+	}
+	for _, e := range events {
+		if err := store.Append(e); err != nil {
+			t.Fatalf("Append(%v) error = %v", e.Type, err)
+		}
+	}
+
+	invalid := []timesheet.Event{
+		{Type: timesheet.EventStop, Interval: timesheet.Interval{End: second.End}},
+		{Type: timesheet.EventStart, Interval: timesheet.Interval{Start: second.Start.Add(time.Hour)}},
+	}
+	for _, e := range invalid {
+		if err := store.Append(e); err == nil {
+			t.Errorf("Append(%v) of invalid event succeeded", e.Type)
+		}
+	}
+	// nothing is recorded if any event is invalid
+	if err := store.Append(timesheet.Event{Type: timesheet.EventDelete, Index: 0}, invalid[0]); err == nil {
+		t.Errorf("Append() of invalid events succeeded")
+	}
+
+	sheet, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if diff := cmp.Diff([]timesheet.Interval{first, second}, sheet.Intervals()); diff != "" {
+		t.Errorf("Load() differs: (-want +got)\n%s", diff)
+	}
+}
+
+func TestOpenOlderRelease(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "tt.db")
+
+	// intervals of older releases have no UTC offsets
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2018, time.September, 1, 8, 0, 0, 0, time.Now().Location())
+	_, err = db.Exec(`
+		CREATE TABLE intervals (
+			id INTEGER PRIMARY KEY,
+			start_time INTEGER NOT NULL,
+			end_time INTEGER,
+			project_id INTEGER
+		);
+		INSERT INTO intervals (start_time) VALUES (?);`, start.UnixNano())
+	db.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := Open(path, dateFormat, timeFormat)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer store.Close()
+
+	end := start.Add(4 * time.Hour)
+	if err := store.Append(timesheet.Event{Type: timesheet.EventStop, Interval: timesheet.Interval{End: end}}); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+
+	sheet, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	want := []timesheet.Interval{{Start: start, End: end}}
+	if diff := cmp.Diff(want, sheet.Intervals()); diff != "" {
+		t.Errorf("Load() differs: (-want +got)\n%s", diff)
+	}
+}
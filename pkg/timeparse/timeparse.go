@@ -0,0 +1,147 @@
+// Package timeparse parses the dates and times given on the command line.
+//
+// Times can be written as
+//
+//	now                     the current time
+//	+1h, -15m               relative to the current time
+//	17:30                   a clock time today in the time format
+//	02.09.2018 17:30        a date and clock time in the date and time format
+//	yesterday 17:30         a day name and clock time
+//	mon 09:00               the most recent monday (or today) and clock time
+//	2018-09-02T17:30:00Z    ISO 8601 with or without seconds and offset
+//
+// Dates are written in the date format, as ISO 8601 date (2018-09-02), as
+// today, yesterday or tomorrow, or as weekday name (mon, monday, ...).
+package timeparse
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// isoLayouts are the accepted ISO 8601 date-times.
+var isoLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+}
+
+const isoDate = "2006-01-02"
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+// Parser parses dates and times relative to Now in the location of Now.
+type Parser struct {
+	DateFormat string // Format of dates.
+	TimeFormat string // Format of clock times.
+	Now        time.Time
+}
+
+// New returns a parser for the given formats relative to now.
+func New(dateFormat, timeFormat string, now time.Time) *Parser {
+	return &Parser{
+		DateFormat: dateFormat,
+		TimeFormat: timeFormat,
+		Now:        now,
+	}
+}
+
+// Time parses a time. Clock times without a date are on the current day.
+func (p *Parser) Time(value string) (time.Time, error) {
+	return p.TimeOn(p.today(), value)
+}
+
+// TimeOn parses a time. Clock times without a date are on the given day.
+func (p *Parser) TimeOn(day time.Time, value string) (time.Time, error) {
+	v := strings.TrimSpace(value)
+
+	if strings.EqualFold(v, "now") {
+		return p.Now, nil
+	}
+	if strings.HasPrefix(v, "+") || strings.HasPrefix(v, "-") {
+		if d, err := time.ParseDuration(v); err == nil {
+			return p.Now.Add(d), nil
+		}
+	}
+	if t, err := p.clock(day, v); err == nil {
+		return t, nil
+	}
+
+	// both the date and the time format may contain spaces
+	for i, c := range v {
+		if c != ' ' {
+			continue
+		}
+		d, err := p.Date(v[:i])
+		if err != nil {
+			continue
+		}
+		if t, err := p.clock(d, strings.TrimSpace(v[i+1:])); err == nil {
+			return t, nil
+		}
+	}
+
+	for _, layout := range isoLayouts {
+		if t, err := time.ParseInLocation(layout, v, p.Now.Location()); err == nil {
+			return t.In(p.Now.Location()), nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time '%s'", value)
+}
+
+// Date parses a date and returns the beginning of the day.
+func (p *Parser) Date(value string) (time.Time, error) {
+	v := strings.TrimSpace(value)
+	today := p.today()
+
+	switch strings.ToLower(v) {
+	case "today":
+		return today, nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), nil
+	}
+
+	if wd, ok := weekdays[strings.ToLower(v)]; ok {
+		days := (int(today.Weekday()) - int(wd) + 7) % 7
+		return today.AddDate(0, 0, -days), nil
+	}
+
+	for _, layout := range []string{p.DateFormat, isoDate} {
+		if layout == "" {
+			continue
+		}
+		if t, err := time.ParseInLocation(layout, v, p.Now.Location()); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid date '%s'", value)
+}
+
+// clock parses a time in the time format on the given day.
+func (p *Parser) clock(day time.Time, value string) (time.Time, error) {
+	t, err := time.Parse(p.TimeFormat, value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	year, month, d := day.Date()
+	return time.Date(year, month, d, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), p.Now.Location()), nil
+}
+
+func (p *Parser) today() time.Time {
+	year, month, day := p.Now.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, p.Now.Location())
+}
@@ -0,0 +1,115 @@
+package timeparse
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParser_Time(t *testing.T) {
+	loc := time.FixedZone("CEST", 2*60*60)
+	// a wednesday
+	now := time.Date(2018, time.September, 5, 14, 30, 15, 0, loc)
+	p := New("02.01.2006", "15:04", now)
+
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{value: "now", want: now},
+		{value: "NOW", want: now},
+		{value: "-15m", want: time.Date(2018, time.September, 5, 14, 15, 15, 0, loc)},
+		{value: "+1h", want: time.Date(2018, time.September, 5, 15, 30, 15, 0, loc)},
+		{value: "-1h30m", want: time.Date(2018, time.September, 5, 13, 0, 15, 0, loc)},
+		{value: "10:15", want: time.Date(2018, time.September, 5, 10, 15, 0, 0, loc)},
+		{value: "03.09.2018 17:30", want: time.Date(2018, time.September, 3, 17, 30, 0, 0, loc)},
+		{value: "yesterday 17:30", want: time.Date(2018, time.September, 4, 17, 30, 0, 0, loc)},
+		{value: "Yesterday  17:30", want: time.Date(2018, time.September, 4, 17, 30, 0, 0, loc)},
+		{value: "today 08:00", want: time.Date(2018, time.September, 5, 8, 0, 0, 0, loc)},
+		{value: "tomorrow 08:00", want: time.Date(2018, time.September, 6, 8, 0, 0, 0, loc)},
+		{value: "mon 09:00", want: time.Date(2018, time.September, 3, 9, 0, 0, 0, loc)},
+		{value: "wednesday 09:00", want: time.Date(2018, time.September, 5, 9, 0, 0, 0, loc)},
+		{value: "thu 09:00", want: time.Date(2018, time.August, 30, 9, 0, 0, 0, loc)},
+		{value: "2018-09-03 09:00", want: time.Date(2018, time.September, 3, 9, 0, 0, 0, loc)},
+		{value: "2018-09-03T09:00", want: time.Date(2018, time.September, 3, 9, 0, 0, 0, loc)},
+		{value: "2018-09-03T09:00:30", want: time.Date(2018, time.September, 3, 9, 0, 30, 0, loc)},
+		{value: "2018-09-03T07:00:00Z", want: time.Date(2018, time.September, 3, 9, 0, 0, 0, loc)},
+		{value: "2018-09-03T09:00+02:00", want: time.Date(2018, time.September, 3, 9, 0, 0, 0, loc)},
+		{value: "99:15", wantErr: true},
+		{value: "yesterday", wantErr: true},
+		{value: "someday 09:00", wantErr: true},
+		{value: "fixing invoices", wantErr: true},
+		{value: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := p.Time(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Time() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("Time() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParser_TimeOn(t *testing.T) {
+	now := time.Date(2018, time.September, 5, 14, 30, 0, 0, time.UTC)
+	day := time.Date(2018, time.September, 1, 0, 0, 0, 0, time.UTC)
+	p := New("02.01.2006", "3:04 PM", now)
+
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{value: "9:15 AM", want: time.Date(2018, time.September, 1, 9, 15, 0, 0, time.UTC)},
+		{value: "02.09.2018 2:00 AM", want: time.Date(2018, time.September, 2, 2, 0, 0, 0, time.UTC)},
+		{value: "now", want: now},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := p.TimeOn(day, tt.value)
+			if err != nil {
+				t.Fatalf("TimeOn() error = %v", err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("TimeOn() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParser_Date(t *testing.T) {
+	// a sunday
+	now := time.Date(2018, time.September, 2, 23, 59, 0, 0, time.UTC)
+	p := New("02.01.2006", "15:04", now)
+
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{value: "today", want: time.Date(2018, time.September, 2, 0, 0, 0, 0, time.UTC)},
+		{value: "yesterday", want: time.Date(2018, time.September, 1, 0, 0, 0, 0, time.UTC)},
+		{value: "tomorrow", want: time.Date(2018, time.September, 3, 0, 0, 0, 0, time.UTC)},
+		{value: "sun", want: time.Date(2018, time.September, 2, 0, 0, 0, 0, time.UTC)},
+		{value: "Monday", want: time.Date(2018, time.August, 27, 0, 0, 0, 0, time.UTC)},
+		{value: "sat", want: time.Date(2018, time.September, 1, 0, 0, 0, 0, time.UTC)},
+		{value: "14.10.2018", want: time.Date(2018, time.October, 14, 0, 0, 0, 0, time.UTC)},
+		{value: "2018-10-14", want: time.Date(2018, time.October, 14, 0, 0, 0, 0, time.UTC)},
+		{value: "14.10.", wantErr: true},
+		{value: "10:00", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := p.Date(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Date() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("Date() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package timesheet
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// AbsenceType is the reason of an absence.
+type AbsenceType string
+
+// Supported absence types. All but comp time are credited with the target
+// of the day, comp time is paid with overtime.
+const (
+	AbsenceVacation AbsenceType = "vacation"
+	AbsenceSick     AbsenceType = "sick"
+	AbsenceHoliday  AbsenceType = "holiday"
+	AbsenceCompTime AbsenceType = "comp-time"
+)
+
+// AbsenceTypes lists the supported absence types.
+var AbsenceTypes = []AbsenceType{AbsenceVacation, AbsenceSick, AbsenceHoliday, AbsenceCompTime}
+
+// Absence is a day or half a day off work.
+type Absence struct {
+	Date time.Time // Day of the absence, a calendar date at midnight UTC.
+	Type AbsenceType
+	Half bool   // Only half of the day is taken off.
+	Note string // Optional free text, ie. the name of a holiday.
+}
+
+// fraction returns the part of the day taken off.
+func (a Absence) fraction() float64 {
+	if a.Half {
+		return 0.5
+	}
+	return 1
+}
+
+// Absences returns all absences ordered by date.
+func (s *Sheet) Absences() []Absence {
+	absences := make([]Absence, len(s.absences))
+	copy(absences, s.absences)
+	return absences
+}
+
+// AddAbsence records the absence. A day can only have one absence of each
+// type.
+func (s *Sheet) AddAbsence(a Absence) error {
+	if !validAbsenceType(a.Type) {
+		return fmt.Errorf("unknown absence type '%s'", a.Type)
+	}
+	a.Date = calendarDate(a.Date)
+
+	for _, other := range s.absences {
+		if other.Type == a.Type && sameDate(other.Date, a.Date) {
+			return fmt.Errorf("%s on %s already recorded", a.Type, a.Date.Format(s.DateFormat))
+		}
+	}
+
+	i := sort.Search(len(s.absences), func(i int) bool {
+		return a.Date.Before(s.absences[i].Date)
+	})
+	s.absences = append(s.absences, Absence{})
+	copy(s.absences[i+1:], s.absences[i:])
+	s.absences[i] = a
+
+	return nil
+}
+
+// RemoveAbsence deletes the n-th absence as returned by Absences.
+func (s *Sheet) RemoveAbsence(n int) error {
+	if n < 0 || n >= len(s.absences) {
+		return fmt.Errorf("absence %d does not exist", n)
+	}
+	s.absences = append(s.absences[:n], s.absences[n+1:]...)
+	return nil
+}
+
+// VacationDays returns the vacation days taken in the year. Public holidays
+// and days without target don't count, without targets all other days
+// count.
+func (s *Sheet) VacationDays(year int, targets Targets) float64 {
+	days := absencesByDay(s.absences)
+
+	var taken float64
+	for _, a := range s.absences {
+		if a.Type != AbsenceVacation || a.Date.Year() != year || targets != nil && targets[a.Date.Weekday()] == 0 {
+			continue
+		}
+		if hasAbsence(days[dayKey(a.Date)], AbsenceHoliday) {
+			continue
+		}
+		taken += a.fraction()
+	}
+
+	return taken
+}
+
+// credit returns the time credited for the absences of a day, at most the
+// target of the day.
+func credit(target time.Duration, absences []Absence) time.Duration {
+	var fraction float64
+	for _, a := range absences {
+		if a.Type != AbsenceCompTime {
+			fraction += a.fraction()
+		}
+	}
+	if fraction > 1 {
+		fraction = 1
+	}
+	return time.Duration(float64(target) * fraction)
+}
+
+// absencesByDay maps the days to their absences.
+func absencesByDay(absences []Absence) map[string][]Absence {
+	days := map[string][]Absence{}
+	for _, a := range absences {
+		days[dayKey(a.Date)] = append(days[dayKey(a.Date)], a)
+	}
+	return days
+}
+
+func hasAbsence(absences []Absence, t AbsenceType) bool {
+	for _, a := range absences {
+		if a.Type == t {
+			return true
+		}
+	}
+	return false
+}
+
+func validAbsenceType(t AbsenceType) bool {
+	for _, valid := range AbsenceTypes {
+		if t == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// calendarDate returns the date of t at midnight UTC. Absences are days
+// which don't depend on a time zone.
+func calendarDate(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// dateIn returns the start of the calendar date in loc.
+func dateIn(date time.Time, loc *time.Location) time.Time {
+	year, month, day := date.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, loc)
+}
+
+// dayKey identifies the day of t.
+func dayKey(t time.Time) string {
+	return t.Format(fileDate)
+}
@@ -0,0 +1,145 @@
+package timesheet
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_Sheet_AddAbsence(t *testing.T) {
+	loc := time.Now().Location()
+	sheet := NewSheet("02.01.2006", "15:04", nil)
+
+	absences := []Absence{
+		{Date: time.Date(2018, time.September, 4, 0, 0, 0, 0, loc), Type: AbsenceSick},
+		{Date: time.Date(2018, time.September, 3, 12, 30, 0, 0, loc), Type: AbsenceVacation, Half: true},
+		{Date: time.Date(2018, time.September, 3, 0, 0, 0, 0, loc), Type: AbsenceCompTime, Half: true},
+	}
+	for _, a := range absences {
+		if err := sheet.AddAbsence(a); err != nil {
+			t.Fatalf("AddAbsence() error = %v", err)
+		}
+	}
+
+	want := []Absence{
+		{Date: time.Date(2018, time.September, 3, 0, 0, 0, 0, time.UTC), Type: AbsenceVacation, Half: true},
+		{Date: time.Date(2018, time.September, 3, 0, 0, 0, 0, time.UTC), Type: AbsenceCompTime, Half: true},
+		{Date: time.Date(2018, time.September, 4, 0, 0, 0, 0, time.UTC), Type: AbsenceSick},
+	}
+	if diff := cmp.Diff(want, sheet.Absences()); diff != "" {
+		t.Errorf("Absences() differs: (-want +got)\n%s", diff)
+	}
+
+	invalid := []Absence{
+		{Date: time.Date(2018, time.September, 4, 8, 0, 0, 0, loc), Type: AbsenceSick, Half: true},
+		{Date: time.Date(2018, time.September, 5, 0, 0, 0, 0, loc), Type: "party"},
+	}
+	for _, a := range invalid {
+		if err := sheet.AddAbsence(a); err == nil {
+			t.Errorf("AddAbsence(%v) succeeded", a)
+		}
+	}
+
+	if err := sheet.RemoveAbsence(1); err != nil {
+		t.Fatalf("RemoveAbsence() error = %v", err)
+	}
+	if diff := cmp.Diff([]Absence{want[0], want[2]}, sheet.Absences()); diff != "" {
+		t.Errorf("Absences() after RemoveAbsence() differs: (-want +got)\n%s", diff)
+	}
+	if err := sheet.RemoveAbsence(2); err == nil {
+		t.Errorf("RemoveAbsence() of missing absence succeeded")
+	}
+}
+
+func Test_Sheet_VacationDays(t *testing.T) {
+	loc := time.Now().Location()
+	sheet := NewSheet("02.01.2006", "15:04", nil)
+	for day := 22; day <= 31; day++ {
+		date := time.Date(2026, time.December, day, 0, 0, 0, 0, loc)
+		if err := sheet.AddAbsence(Absence{Date: date, Type: AbsenceVacation, Half: day == 31}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, day := range []int{25, 26} {
+		date := time.Date(2026, time.December, day, 0, 0, 0, 0, loc)
+		if err := sheet.AddAbsence(Absence{Date: date, Type: AbsenceHoliday}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	next := Absence{Date: time.Date(2027, time.January, 4, 0, 0, 0, 0, loc), Type: AbsenceVacation}
+	if err := sheet.AddAbsence(next); err != nil {
+		t.Fatal(err)
+	}
+
+	targets, _ := ParseTargets("8h")
+	// 22nd to 24th, 28th to 30th and half of the 31st, the 25th is a
+	// holiday, the 26th and 27th a weekend
+	if got := sheet.VacationDays(2026, targets); got != 6.5 {
+		t.Errorf("VacationDays() = %v, want %v", got, 6.5)
+	}
+	if got := sheet.VacationDays(2026, nil); got != 7.5 {
+		t.Errorf("VacationDays() without targets = %v, want %v", got, 7.5)
+	}
+	if got := sheet.VacationDays(2027, targets); got != 1 {
+		t.Errorf("VacationDays() = %v, want %v", got, 1)
+	}
+}
+
+func Test_Sheet_Balance_Absences(t *testing.T) {
+	loc := time.Now().Location()
+	targets, _ := ParseTargets("8h")
+	sheet := NewSheet("02.01.2006", "15:04", []Interval{
+		{
+			Start: time.Date(2018, time.September, 3, 8, 0, 0, 0, loc),
+			End:   time.Date(2018, time.September, 3, 12, 0, 0, 0, loc),
+		},
+	})
+	absences := []Absence{
+		{Date: time.Date(2018, time.September, 3, 0, 0, 0, 0, loc), Type: AbsenceVacation, Half: true},
+		{Date: time.Date(2018, time.September, 4, 0, 0, 0, 0, loc), Type: AbsenceHoliday},
+		{Date: time.Date(2018, time.September, 4, 0, 0, 0, 0, loc), Type: AbsenceSick},
+		{Date: time.Date(2018, time.September, 5, 0, 0, 0, 0, loc), Type: AbsenceCompTime},
+		{Date: time.Date(2018, time.September, 8, 0, 0, 0, 0, loc), Type: AbsenceSick},
+	}
+	for _, a := range absences {
+		if err := sheet.AddAbsence(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := sheet.Balance(
+		time.Date(2018, time.September, 3, 0, 0, 0, 0, loc),
+		time.Date(2018, time.September, 10, 0, 0, 0, 0, loc),
+		targets, 15*time.Minute,
+	)
+	// half of monday, all of tuesday, nothing for comp time and the weekend
+	want := Balance{Worked: 4 * time.Hour, Target: 40 * time.Hour, Credit: 12 * time.Hour}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Balance() differs: (-want +got)\n%s", diff)
+	}
+}
+
+func Test_Sheet_PrintMonth_AbsencesLocation(t *testing.T) {
+	targets, _ := ParseTargets("8h")
+	sheet := NewSheet("02.01.2006", "15:04", nil)
+	// recorded at local midnight, which is still the day before in New York
+	if err := sheet.AddAbsence(Absence{Date: time.Date(2026, time.October, 1, 0, 0, 0, 0, cest), Type: AbsenceVacation}); err != nil {
+		t.Fatal(err)
+	}
+
+	newYork := time.FixedZone("EDT", -4*60*60)
+	for _, loc := range []*time.Location{nil, newYork} {
+		sheet.Location = loc
+
+		var got bytes.Buffer
+		if err := sheet.PrintMonth(2026, time.October, PrintOptions{Targets: targets}, &got); err != nil {
+			t.Fatalf("PrintMonth() error = %v", err)
+		}
+		if !strings.Contains(got.String(), "01.10.2026") || strings.Contains(got.String(), "30.09.2026") {
+			t.Errorf("PrintMonth() in %v doesn't list the vacation on 01.10.2026:\n%s", loc, got.String())
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package timesheet
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Targets are the hours to work on each day of the week. Days missing in
+// the map have no target.
+type Targets map[time.Weekday]time.Duration
+
+var weekdays = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// ParseTargets parses targets like "8h" for monday to friday, "40h/week"
+// split evenly over monday to friday or "mon-thu=8h,fri=6h".
+func ParseTargets(value string) (Targets, error) {
+	targets := Targets{}
+
+	if strings.HasSuffix(value, "/week") {
+		d, err := time.ParseDuration(strings.TrimSuffix(value, "/week"))
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("invalid target '%s'", value)
+		}
+		for day := time.Monday; day <= time.Friday; day++ {
+			targets[day] = d / 5
+		}
+		return targets, nil
+	}
+
+	if !strings.Contains(value, "=") {
+		value = "mon-fri=" + value
+	}
+
+	for _, part := range strings.Split(value, ",") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid target '%s'", part)
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(kv[1]))
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("invalid target '%s'", part)
+		}
+
+		days := strings.SplitN(strings.TrimSpace(kv[0]), "-", 2)
+		first, ok := parseWeekday(days[0])
+		if !ok {
+			return nil, fmt.Errorf("invalid weekday '%s'", days[0])
+		}
+		last := first
+		if len(days) == 2 {
+			if last, ok = parseWeekday(days[1]); !ok {
+				return nil, fmt.Errorf("invalid weekday '%s'", days[1])
+			}
+		}
+
+		for day := first; ; day = (day + 1) % 7 {
+			targets[day] = d
+			if day == last {
+				break
+			}
+		}
+	}
+
+	return targets, nil
+}
+
+// parseWeekday parses a weekday name like "mon" or "Monday".
+func parseWeekday(name string) (time.Weekday, bool) {
+	name = strings.ToLower(name)
+	for day, short := range weekdays {
+		if name == short || name == strings.ToLower(time.Weekday(day).String()) {
+			return time.Weekday(day), true
+		}
+	}
+	return 0, false
+}
+
+// String returns the targets in the format accepted by ParseTargets.
+func (t Targets) String() string {
+	var parts []string
+	for day := time.Monday; day <= time.Saturday+1; day++ {
+		if d, ok := t[day%7]; ok {
+			parts = append(parts, weekdays[day%7]+"="+d.String())
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// Between returns the sum of the targets of the days from the date of from
+// up to the day before the date of to.
+func (t Targets) Between(from, to time.Time) time.Duration {
+	var sum time.Duration
+	for day := midnight(from); day.Before(midnight(to)); day = day.AddDate(0, 0, 1) {
+		sum += t[day.Weekday()]
+	}
+	return sum
+}
+
+// Balance compares the time worked with the targets.
+type Balance struct {
+	Worked time.Duration // Time tracked.
+	Target time.Duration // Time which should have been worked.
+	Credit time.Duration // Time credited for absences.
+}
+
+// Overtime returns the time worked and credited beyond the target. It is
+// negative for undertime.
+func (b Balance) Overtime() time.Duration {
+	return b.Worked + b.Credit - b.Target
+}
+
+// Balance returns the time worked, the targets and the credit for absences
+// of the days between from and to. Intervals are rounded to roundTo like
+// in the output.
+func (s *Sheet) Balance(from, to time.Time, targets Targets, roundTo time.Duration) Balance {
+	p := balancePeriod{from: midnight(from), to: to, targets: targets, absences: absencesByDay(s.absences)}
+	b := Balance{Target: p.target(from, to), Credit: p.credit(from, to)}
+
+	for _, i := range s.Range(from, to).intervals {
+		for _, d := range i.In(from.Location()).Round(roundTo).SplitDays() {
+			if !d.Start.Before(from) && d.Start.Before(to) {
+				b.Worked += d.Duration()
+			}
+		}
+	}
+
+	return b
+}
+
+// balancePeriod are the days counted in the balance of the output.
+type balancePeriod struct {
+	from, to time.Time
+	targets  Targets
+	absences map[string][]Absence
+}
+
+// newBalancePeriod returns the period between from and to. Days after now
+// are not counted unless now is zero.
+func newBalancePeriod(from, to time.Time, absences []Absence, opts PrintOptions) *balancePeriod {
+	if opts.Targets == nil || opts.GroupBy != GroupByNone {
+		return nil
+	}
+	if !opts.Now.IsZero() && midnight(opts.Now).AddDate(0, 0, 1).Before(to) {
+		to = midnight(opts.Now).AddDate(0, 0, 1)
+	}
+	return &balancePeriod{from: midnight(from), to: to, targets: opts.Targets, absences: absencesByDay(absences)}
+}
+
+// target returns the targets of the days between from and to which are
+// part of the period.
+func (p *balancePeriod) target(from, to time.Time) time.Duration {
+	from, to = p.clamp(from, to)
+	return p.targets.Between(from, to)
+}
+
+// credit returns the time credited for the absences of the days between
+// from and to which are part of the period.
+func (p *balancePeriod) credit(from, to time.Time) time.Duration {
+	from, to = p.clamp(from, to)
+
+	var sum time.Duration
+	for day := midnight(from); day.Before(midnight(to)); day = day.AddDate(0, 0, 1) {
+		if absences, ok := p.absences[dayKey(day)]; ok {
+			sum += credit(p.targets[day.Weekday()], absences)
+		}
+	}
+	return sum
+}
+
+// due returns the time which has to be worked between from and to.
+func (p *balancePeriod) due(from, to time.Time) time.Duration {
+	return p.target(from, to) - p.credit(from, to)
+}
+
+// clamp limits from and to to the period.
+func (p *balancePeriod) clamp(from, to time.Time) (time.Time, time.Time) {
+	if from.Before(p.from) {
+		from = p.from
+	}
+	if to.After(p.to) {
+		to = p.to
+	}
+	return from, to
+}
+
+// midnight returns the start of the day of t.
+func midnight(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,102 @@
+package timesheet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseTargets(t *testing.T) {
+	workdays := Targets{
+		time.Monday:    8 * time.Hour,
+		time.Tuesday:   8 * time.Hour,
+		time.Wednesday: 8 * time.Hour,
+		time.Thursday:  8 * time.Hour,
+		time.Friday:    8 * time.Hour,
+	}
+
+	tests := []struct {
+		value   string
+		want    Targets
+		wantErr bool
+	}{
+		{value: "8h", want: workdays},
+		{value: "40h/week", want: workdays},
+		{value: "mon-fri=8h", want: workdays},
+		{
+			value: "mon-thu=8h,fri=6h",
+			want: Targets{
+				time.Monday:    8 * time.Hour,
+				time.Tuesday:   8 * time.Hour,
+				time.Wednesday: 8 * time.Hour,
+				time.Thursday:  8 * time.Hour,
+				time.Friday:    6 * time.Hour,
+			},
+		},
+		{
+			value: "Saturday-Monday=4h",
+			want: Targets{
+				time.Saturday: 4 * time.Hour,
+				time.Sunday:   4 * time.Hour,
+				time.Monday:   4 * time.Hour,
+			},
+		},
+		{value: "8", wantErr: true},
+		{value: "mon-fry=8h", wantErr: true},
+		{value: "mon=-1h", wantErr: true},
+		{value: "mon", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseTargets(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTargets() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("ParseTargets() differs: (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTargetsString(t *testing.T) {
+	targets := Targets{time.Sunday: time.Hour, time.Monday: 8 * time.Hour, time.Friday: 6 * time.Hour}
+	want := "mon=8h0m0s,fri=6h0m0s,sun=1h0m0s"
+	if got := targets.String(); got != want {
+		t.Errorf("String() = %v, want %v", got, want)
+	}
+}
+
+func Test_Sheet_Balance(t *testing.T) {
+	targets, _ := ParseTargets("8h")
+	sheet := NewSheet("02.01.2006", "15:04", []Interval{
+		{
+			Start: time.Date(2018, time.August, 31, 9, 0, 0, 0, time.Now().Location()),
+			End:   time.Date(2018, time.August, 31, 17, 0, 0, 0, time.Now().Location()),
+		},
+		{
+			Start: time.Date(2018, time.September, 2, 22, 0, 0, 0, time.Now().Location()),
+			End:   time.Date(2018, time.September, 3, 2, 0, 0, 0, time.Now().Location()),
+		},
+		{
+			Start: time.Date(2018, time.September, 3, 9, 7, 0, 0, time.Now().Location()),
+			End:   time.Date(2018, time.September, 3, 15, 0, 0, 0, time.Now().Location()),
+		},
+		{
+			Start: time.Date(2018, time.September, 5, 9, 0, 0, 0, time.Now().Location()),
+		},
+	})
+
+	from := time.Date(2018, time.September, 3, 0, 0, 0, 0, time.Now().Location())
+	to := time.Date(2018, time.September, 6, 0, 0, 0, 0, time.Now().Location())
+
+	got := sheet.Balance(from, to, targets, 15*time.Minute)
+	want := Balance{Worked: 8 * time.Hour, Target: 24 * time.Hour}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Balance() differs: (-want +got)\n%s", diff)
+	}
+	if got.Overtime() != -16*time.Hour {
+		t.Errorf("Overtime() = %v, want %v", got.Overtime(), -16*time.Hour)
+	}
+}
@@ -0,0 +1,237 @@
+package timesheet
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// CSVColumns are the columns of intervals in CSV files. Tags are separated
+// by commas.
+var CSVColumns = []string{"date", "start", "end", "project", "tags", "note"}
+
+// CSVFormat describes the layout of a CSV file.
+type CSVFormat struct {
+	Columns    []string       // Columns in the order of the file. If empty the header or CSVColumns.
+	Comma      rune           // Field delimiter.
+	DateFormat string         // Format of the date column.
+	TimeFormat string         // Format of the start and end columns.
+	Header     bool           // Whether the first row names the columns.
+	Location   *time.Location // Time zone of the dates and times, the local one if nil.
+}
+
+// columns returns the columns of the file, header is the first row if the
+// file has one.
+func (f CSVFormat) columns(header []string) []string {
+	if len(f.Columns) > 0 {
+		return f.Columns
+	}
+	if f.Header && header != nil {
+		columns := make([]string, len(header))
+		for n, c := range header {
+			columns[n] = strings.ToLower(strings.TrimSpace(c))
+		}
+		return columns
+	}
+	return CSVColumns
+}
+
+// DefaultCSVFormat returns the layout written by tt.
+func DefaultCSVFormat() CSVFormat {
+	return CSVFormat{
+		Comma:      ',',
+		DateFormat: "2006-01-02",
+		TimeFormat: "15:04",
+		Header:     true,
+	}
+}
+
+// CSVRow is an interval read from a row of a CSV file.
+type CSVRow struct {
+	Row      int // Number of the row in the file, starting with 1.
+	Interval Interval
+	Err      error // Why the row is not a valid interval.
+}
+
+// WriteCSV writes a row for every interval. The end column includes the
+// date if the interval ends on a later day and is empty while the interval
+// is open.
+func WriteCSV(w io.Writer, intervals []Interval, format CSVFormat) error {
+	format.Columns = format.columns(nil)
+	if err := format.validate(false); err != nil {
+		return err
+	}
+
+	out := csv.NewWriter(w)
+	out.Comma = format.Comma
+
+	if format.Header {
+		if err := out.Write(format.Columns); err != nil {
+			return err
+		}
+	}
+	for _, i := range intervals {
+		i = i.In(format.location())
+		row := make([]string, len(format.Columns))
+		for n, column := range format.Columns {
+			switch column {
+			case "date":
+				row[n] = i.Start.Format(format.DateFormat)
+			case "start":
+				row[n] = i.Start.Format(format.TimeFormat)
+			case "end":
+				if i.Open() {
+					break
+				}
+				row[n] = i.End.Format(format.TimeFormat)
+				if !sameDate(i.Start, i.End) {
+					row[n] = i.End.Format(format.DateFormat + " " + format.TimeFormat)
+				}
+			case "project":
+				row[n] = i.Project
+			case "tags":
+				row[n] = strings.Join(i.Tags, ",")
+			case "note":
+				row[n] = i.Note
+			}
+		}
+		if err := out.Write(row); err != nil {
+			return err
+		}
+	}
+
+	out.Flush()
+	return out.Error()
+}
+
+// ReadCSV reads an interval from every row. Columns which are not in
+// CSVColumns are skipped. Rows which are no valid interval are returned
+// with the reason, whether the intervals overlap is not checked.
+func ReadCSV(r io.Reader, format CSVFormat) ([]CSVRow, error) {
+	// without columns they are read from the header
+	fromHeader := len(format.Columns) == 0 && format.Header
+	if !fromHeader {
+		format.Columns = format.columns(nil)
+		if err := format.validate(true); err != nil {
+			return nil, err
+		}
+	}
+
+	in := csv.NewReader(r)
+	in.Comma = format.Comma
+	in.FieldsPerRecord = -1
+
+	var rows []CSVRow
+	for n := 1; ; n++ {
+		record, err := in.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if n == 1 && fromHeader {
+			format.Columns = format.columns(record)
+			if err := format.validate(true); err != nil {
+				return nil, err
+			}
+		}
+		if n == 1 && format.Header {
+			continue
+		}
+
+		interval, err := format.parse(record)
+		rows = append(rows, CSVRow{Row: n, Interval: interval, Err: err})
+	}
+
+	return rows, nil
+}
+
+// parse returns the interval of a record.
+func (f CSVFormat) parse(record []string) (Interval, error) {
+	if len(record) < len(f.Columns) {
+		return Interval{}, fmt.Errorf("expected %d columns, got %d", len(f.Columns), len(record))
+	}
+
+	fields := map[string]string{}
+	for n, column := range f.Columns {
+		fields[column] = strings.TrimSpace(record[n])
+	}
+
+	loc := f.location()
+	date, err := time.ParseInLocation(f.DateFormat, fields["date"], loc)
+	if err != nil {
+		return Interval{}, fmt.Errorf("invalid date '%s'", fields["date"])
+	}
+
+	var i Interval
+	if i.Start, err = f.timeOn(date, fields["start"]); err != nil {
+		return Interval{}, fmt.Errorf("invalid start time '%s'", fields["start"])
+	}
+	if fields["end"] != "" {
+		if i.End, err = f.timeOn(date, fields["end"]); err != nil {
+			// intervals ending on a later day include the date
+			if i.End, err = time.ParseInLocation(f.DateFormat+" "+f.TimeFormat, fields["end"], loc); err != nil {
+				return Interval{}, fmt.Errorf("invalid end time '%s'", fields["end"])
+			}
+		}
+		if i.End.Before(i.Start) {
+			return Interval{}, fmt.Errorf("end time %s is earlier as start time %s", fields["end"], fields["start"])
+		}
+	}
+
+	i.Project = fields["project"]
+	for _, tag := range strings.Split(fields["tags"], ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			i.Tags = append(i.Tags, tag)
+		}
+	}
+	i.Note = fields["note"]
+
+	return i, nil
+}
+
+// timeOn parses a time on the day of date.
+func (f CSVFormat) timeOn(date time.Time, value string) (time.Time, error) {
+	t, err := time.ParseInLocation(f.TimeFormat, value, date.Location())
+	if err != nil {
+		return time.Time{}, err
+	}
+	year, month, day := date.Date()
+	return time.Date(year, month, day, t.Hour(), t.Minute(), t.Second(), 0, date.Location()), nil
+}
+
+// location returns the time zone of the dates and times.
+func (f CSVFormat) location() *time.Location {
+	if f.Location == nil {
+		return time.Local
+	}
+	return f.Location
+}
+
+// validate checks the columns of the format. Reading requires a date and a
+// start column.
+func (f CSVFormat) validate(read bool) error {
+	known := map[string]bool{}
+	for _, c := range CSVColumns {
+		known[c] = true
+	}
+
+	seen := map[string]bool{}
+	for _, c := range f.Columns {
+		if !known[c] && !read {
+			return fmt.Errorf("unknown column '%s'", c)
+		}
+		if known[c] && seen[c] {
+			return fmt.Errorf("duplicate column '%s'", c)
+		}
+		seen[c] = true
+	}
+	if read && (!seen["date"] || !seen["start"]) {
+		return fmt.Errorf("missing date or start column")
+	}
+
+	return nil
+}
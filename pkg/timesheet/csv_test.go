@@ -0,0 +1,226 @@
+package timesheet
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCSVRoundTrip(t *testing.T) {
+	intervals := []Interval{
+		{
+			Start:   time.Date(2018, time.September, 3, 9, 0, 0, 0, time.Now().Location()),
+			End:     time.Date(2018, time.September, 3, 12, 30, 0, 0, time.Now().Location()),
+			Project: "acme",
+			Tags:    []string{"billable", "support"},
+			Note:    "invoices, \"urgent\"",
+		},
+		{
+			Start: time.Date(2018, time.September, 4, 22, 0, 0, 0, time.Now().Location()),
+			End:   time.Date(2018, time.September, 5, 2, 0, 0, 0, time.Now().Location()),
+		},
+		{
+			Start: time.Date(2018, time.September, 6, 8, 15, 0, 0, time.Now().Location()),
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, intervals, DefaultCSVFormat()); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+
+	want := "date,start,end,project,tags,note\n" +
+		"2018-09-03,09:00,12:30,acme,\"billable,support\",\"invoices, \"\"urgent\"\"\"\n" +
+		"2018-09-04,22:00,2018-09-05 02:00,,,\n" +
+		"2018-09-06,08:15,,,,\n"
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("WriteCSV() differs: (-want +got)\n%s", diff)
+	}
+
+	rows, err := ReadCSV(&buf, DefaultCSVFormat())
+	if err != nil {
+		t.Fatalf("ReadCSV() error = %v", err)
+	}
+	var got []Interval
+	for _, row := range rows {
+		if row.Err != nil {
+			t.Errorf("ReadCSV() row %d error = %v", row.Row, row.Err)
+		}
+		got = append(got, row.Interval)
+	}
+	if diff := cmp.Diff(intervals, got); diff != "" {
+		t.Errorf("ReadCSV() differs: (-want +got)\n%s", diff)
+	}
+}
+
+func TestCSVLocation(t *testing.T) {
+	intervals := []Interval{
+		{
+			Start: time.Date(2018, time.September, 3, 9, 0, 0, 0, cest),
+			End:   time.Date(2018, time.September, 3, 17, 0, 0, 0, cest),
+		},
+	}
+
+	format := DefaultCSVFormat()
+	format.Location = time.FixedZone("EDT", -4*60*60)
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, intervals, format); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+
+	want := "date,start,end,project,tags,note\n" +
+		"2018-09-03,03:00,11:00,,,\n"
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("WriteCSV() differs: (-want +got)\n%s", diff)
+	}
+
+	rows, err := ReadCSV(&buf, format)
+	if err != nil {
+		t.Fatalf("ReadCSV() error = %v", err)
+	}
+	if len(rows) != 1 || rows[0].Err != nil {
+		t.Fatalf("ReadCSV() = %v, want one interval", rows)
+	}
+	if diff := cmp.Diff(intervals[0], rows[0].Interval); diff != "" {
+		t.Errorf("ReadCSV() differs: (-want +got)\n%s", diff)
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	file, err := os.Open("testdata/spreadsheet.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	format := CSVFormat{
+		Columns:    []string{"date", "project", "start", "end", "hours", "note"},
+		Comma:      ';',
+		DateFormat: "02.01.2006",
+		TimeFormat: "15:04",
+		Header:     true,
+	}
+	rows, err := ReadCSV(file, format)
+	if err != nil {
+		t.Fatalf("ReadCSV() error = %v", err)
+	}
+
+	type result struct {
+		Row      int
+		Interval Interval
+		Err      string
+	}
+	var got []result
+	for _, row := range rows {
+		r := result{Row: row.Row, Interval: row.Interval}
+		if row.Err != nil {
+			r.Err = row.Err.Error()
+		}
+		got = append(got, r)
+	}
+
+	want := []result{
+		{
+			Row: 2,
+			Interval: Interval{
+				Start:   time.Date(2018, time.September, 3, 9, 0, 0, 0, time.Now().Location()),
+				End:     time.Date(2018, time.September, 3, 12, 30, 0, 0, time.Now().Location()),
+				Project: "acme",
+				Note:    "invoices; reminders",
+			},
+		},
+		{
+			Row: 3,
+			Interval: Interval{
+				Start: time.Date(2018, time.September, 3, 13, 0, 0, 0, time.Now().Location()),
+				End:   time.Date(2018, time.September, 3, 17, 0, 0, 0, time.Now().Location()),
+			},
+		},
+		{
+			Row: 4,
+			Interval: Interval{
+				Start:   time.Date(2018, time.September, 4, 22, 0, 0, 0, time.Now().Location()),
+				End:     time.Date(2018, time.September, 5, 2, 0, 0, 0, time.Now().Location()),
+				Project: "acme",
+				Note:    "night shift",
+			},
+		},
+		{Row: 5, Err: "invalid date '31.09.2018'"},
+		{Row: 6, Err: "invalid start time '9 Uhr'"},
+		{Row: 7, Err: "end time 09:00 is earlier as start time 10:00"},
+		{Row: 8, Err: "expected 6 columns, got 2"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ReadCSV() differs: (-want +got)\n%s", diff)
+	}
+}
+
+func TestReadCSVWithoutHeader(t *testing.T) {
+	data := "03.09.2018;acme;09:00;12:30;3,5\n" +
+		"03.09.2018;;13:00;17:00;4\n"
+
+	format := CSVFormat{
+		Columns:    []string{"date", "project", "start", "end", "hours"},
+		Comma:      ';',
+		DateFormat: "02.01.2006",
+		TimeFormat: "15:04",
+		Header:     false,
+	}
+	rows, err := ReadCSV(strings.NewReader(data), format)
+	if err != nil {
+		t.Fatalf("ReadCSV() error = %v", err)
+	}
+
+	want := []CSVRow{
+		{
+			Row: 1,
+			Interval: Interval{
+				Start:   time.Date(2018, time.September, 3, 9, 0, 0, 0, time.Now().Location()),
+				End:     time.Date(2018, time.September, 3, 12, 30, 0, 0, time.Now().Location()),
+				Project: "acme",
+			},
+		},
+		{
+			Row: 2,
+			Interval: Interval{
+				Start: time.Date(2018, time.September, 3, 13, 0, 0, 0, time.Now().Location()),
+				End:   time.Date(2018, time.September, 3, 17, 0, 0, 0, time.Now().Location()),
+			},
+		},
+	}
+	if diff := cmp.Diff(want, rows); diff != "" {
+		t.Errorf("ReadCSV() differs: (-want +got)\n%s", diff)
+	}
+}
+
+func TestCSVFormatInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		columns []string
+		read    bool
+	}{
+		{name: "unknown column", columns: []string{"date", "start", "hours"}},
+		{name: "duplicate column", columns: []string{"date", "start", "start"}, read: true},
+		{name: "missing start", columns: []string{"date", "end"}, read: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format := DefaultCSVFormat()
+			format.Columns = tt.columns
+			if tt.read {
+				if _, err := ReadCSV(&bytes.Buffer{}, format); err == nil {
+					t.Errorf("ReadCSV() succeeded")
+				}
+				return
+			}
+			if err := WriteCSV(&bytes.Buffer{}, nil, format); err == nil {
+				t.Errorf("WriteCSV() succeeded")
+			}
+		})
+	}
+}
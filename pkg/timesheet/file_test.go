@@ -0,0 +1,115 @@
+package timesheet
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestWriteFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "tt.json")
+
+	for _, content := range []string{"1", "2", "3", "4"} {
+		err := writeFile(path, 2, func(w io.Writer) error {
+			_, err := io.WriteString(w, content)
+			return err
+		})
+		if err != nil {
+			t.Fatalf("writeFile() error = %v", err)
+		}
+	}
+
+	err = writeFile(path, 2, func(w io.Writer) error {
+		io.WriteString(w, "broken")
+		return fmt.Errorf("disk full")
+	})
+	if err == nil {
+		t.Errorf("writeFile() error = nil, want error")
+	}
+
+	want := map[string]string{
+		"tt.json":   "4",
+		"tt.json.1": "3",
+		"tt.json.2": "2",
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != len(want) {
+		var names []string
+		for _, f := range files {
+			names = append(names, f.Name())
+		}
+		t.Errorf("writeFile() left files %v, want %d files", names, len(want))
+	}
+
+	for name, content := range want {
+		got := string(readFile(t, filepath.Join(dir, name)))
+		if got != content {
+			t.Errorf("%s = %q, want %q", name, got, content)
+		}
+	}
+}
+
+func TestWriteFileMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not supported on windows")
+	}
+
+	dir, err := ioutil.TempDir("", "tt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	write := func(w io.Writer) error {
+		_, err := io.WriteString(w, "{}")
+		return err
+	}
+
+	// new files are readable by everyone
+	path := filepath.Join(dir, "new.json")
+	if err := writeFile(path, 1, write); err != nil {
+		t.Fatalf("writeFile() error = %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0644 {
+		t.Errorf("writeFile() mode = %v, want %v", info.Mode().Perm(), os.FileMode(0644))
+	}
+
+	// replaced files keep their mode
+	path = filepath.Join(dir, "private.json")
+	if err := ioutil.WriteFile(path, []byte("{}"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFile(path, 1, write); err != nil {
+		t.Fatalf("writeFile() error = %v", err)
+	}
+	for _, p := range []string{path, backupPath(path, 1)} {
+		info, err := os.Stat(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != 0600 {
+			t.Errorf("%s mode = %v, want %v", filepath.Base(p), info.Mode().Perm(), os.FileMode(0600))
+		}
+	}
+}
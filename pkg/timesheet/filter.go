@@ -0,0 +1,70 @@
+package timesheet
+
+import "sort"
+
+// Filter selects intervals by project and tags. Empty fields match every
+// interval.
+type Filter struct {
+	Project string   // Only match intervals of this project.
+	Tags    []string // Only match intervals with all of these tags.
+}
+
+// Match reports whether the interval passes the filter.
+func (f Filter) Match(i Interval) bool {
+	if f.Project != "" && f.Project != i.Project {
+		return false
+	}
+	for _, tag := range f.Tags {
+		if !i.HasTag(tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// GroupBy defines how intervals are grouped in the output.
+type GroupBy string
+
+// Supported groupings.
+const (
+	GroupByNone    GroupBy = ""
+	GroupByProject GroupBy = "project"
+	GroupByTag     GroupBy = "tag"
+)
+
+// group is a named subset of intervals.
+type group struct {
+	name      string
+	intervals []Interval
+}
+
+// groupIntervals splits the intervals into groups ordered by name. With
+// GroupByTag an interval is part of every group of its tags.
+func groupIntervals(intervals []Interval, by GroupBy) []group {
+	if by == GroupByNone {
+		return []group{{intervals: intervals}}
+	}
+
+	byName := map[string][]Interval{}
+	for _, i := range intervals {
+		switch by {
+		case GroupByProject:
+			byName[i.Project] = append(byName[i.Project], i)
+		case GroupByTag:
+			if len(i.Tags) == 0 {
+				byName[""] = append(byName[""], i)
+			}
+			for _, tag := range i.Tags {
+				byName[tag] = append(byName[tag], i)
+			}
+		}
+	}
+
+	var groups []group
+	for name, intervals := range byName {
+		groups = append(groups, group{name: name, intervals: intervals})
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].name < groups[j].name })
+
+	return groups
+}
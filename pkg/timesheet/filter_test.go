@@ -0,0 +1,117 @@
+package timesheet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFilterMatch(t *testing.T) {
+	interval := Interval{
+		Start:   time.Date(2018, time.September, 1, 10, 0, 0, 0, time.Now().Location()),
+		Project: "acme",
+		Tags:    []string{"review", "meeting"},
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   bool
+	}{
+		{
+			name:   "empty",
+			filter: Filter{},
+			want:   true,
+		},
+		{
+			name:   "project",
+			filter: Filter{Project: "acme"},
+			want:   true,
+		},
+		{
+			name:   "other project",
+			filter: Filter{Project: "globex"},
+			want:   false,
+		},
+		{
+			name:   "tags",
+			filter: Filter{Tags: []string{"meeting", "review"}},
+			want:   true,
+		},
+		{
+			name:   "missing tag",
+			filter: Filter{Tags: []string{"review", "bugfix"}},
+			want:   false,
+		},
+		{
+			name:   "project and tag",
+			filter: Filter{Project: "acme", Tags: []string{"review"}},
+			want:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Match(interval); got != tt.want {
+				t.Errorf("Filter.Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGroupIntervals(t *testing.T) {
+	acme := Interval{
+		Start:   time.Date(2018, time.September, 1, 8, 0, 0, 0, time.Now().Location()),
+		Project: "acme",
+		Tags:    []string{"review", "meeting"},
+	}
+	globex := Interval{
+		Start:   time.Date(2018, time.September, 1, 10, 0, 0, 0, time.Now().Location()),
+		Project: "globex",
+		Tags:    []string{"review"},
+	}
+	none := Interval{
+		Start: time.Date(2018, time.September, 1, 12, 0, 0, 0, time.Now().Location()),
+	}
+	intervals := []Interval{acme, globex, none}
+
+	tests := []struct {
+		name string
+		by   GroupBy
+		want []group
+	}{
+		{
+			name: "none",
+			by:   GroupByNone,
+			want: []group{{intervals: intervals}},
+		},
+		{
+			name: "project",
+			by:   GroupByProject,
+			want: []group{
+				{name: "", intervals: []Interval{none}},
+				{name: "acme", intervals: []Interval{acme}},
+				{name: "globex", intervals: []Interval{globex}},
+			},
+		},
+		{
+			name: "tag",
+			by:   GroupByTag,
+			want: []group{
+				{name: "", intervals: []Interval{none}},
+				{name: "meeting", intervals: []Interval{acme}},
+				{name: "review", intervals: []Interval{acme, globex}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := groupIntervals(intervals, tt.by)
+			if diff := cmp.Diff(tt.want, got, cmp.AllowUnexported(group{})); diff != "" {
+				t.Errorf("groupIntervals() differs: (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
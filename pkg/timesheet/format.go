@@ -0,0 +1,155 @@
+package timesheet
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// isoDate is the format of dates in machine-readable output.
+const isoDate = "2006-01-02"
+
+// reportJSON is the output of the json format. Hours are decimal, balances
+// are only set if the report has one.
+type reportJSON struct {
+	From    string      `json:"from"`
+	To      string      `json:"to"`
+	RoundTo int         `json:"round_to"` // Minutes.
+	GroupBy GroupBy     `json:"group_by,omitempty"`
+	Groups  []groupJSON `json:"groups"`
+	Hours   float64     `json:"hours"`
+	Balance *float64    `json:"balance,omitempty"`
+}
+
+type groupJSON struct {
+	Name    string     `json:"name,omitempty"`
+	Weeks   []weekJSON `json:"weeks"`
+	Hours   float64    `json:"hours"`
+	Balance *float64   `json:"balance,omitempty"`
+}
+
+type weekJSON struct {
+	Year    int       `json:"year"`
+	Week    int       `json:"week"`
+	Days    []dayJSON `json:"days"`
+	Hours   float64   `json:"hours"`
+	Balance *float64  `json:"balance,omitempty"`
+}
+
+type dayJSON struct {
+	Date      string         `json:"date"`
+	Intervals []intervalJSON `json:"intervals"`
+	Absences  []fileAbsence  `json:"absences,omitempty"`
+	Hours     float64        `json:"hours"`
+	Balance   *float64       `json:"balance,omitempty"`
+}
+
+type intervalJSON struct {
+	Start   time.Time  `json:"start"`
+	End     *time.Time `json:"end,omitempty"`
+	Hours   float64    `json:"hours"`
+	Project string     `json:"project,omitempty"`
+	Tags    []string   `json:"tags,omitempty"`
+	Note    string     `json:"note,omitempty"`
+}
+
+// jsonFormatter writes the report as a JSON document.
+type jsonFormatter struct{}
+
+func (jsonFormatter) Format(w io.Writer, r Report) error {
+	balance := func(d time.Duration) *float64 {
+		if !r.HasBalance {
+			return nil
+		}
+		hours := d.Hours()
+		return &hours
+	}
+
+	doc := reportJSON{
+		From:    r.From.Format(isoDate),
+		To:      r.LastDay().Format(isoDate),
+		RoundTo: int(r.RoundTo / time.Minute),
+		GroupBy: r.GroupBy,
+		Groups:  []groupJSON{},
+		Hours:   r.Total.Hours(),
+		Balance: balance(r.Balance),
+	}
+	for _, g := range r.Groups {
+		group := groupJSON{Name: g.Name, Weeks: []weekJSON{}, Hours: g.Total.Hours(), Balance: balance(g.Balance)}
+		for _, week := range g.Weeks {
+			wj := weekJSON{Year: week.Year, Week: week.Number, Hours: week.Total.Hours(), Balance: balance(week.Balance)}
+			for _, d := range week.Days {
+				day := dayJSON{Date: d.Date.Format(isoDate), Intervals: []intervalJSON{}, Hours: d.Total.Hours(), Balance: balance(d.Balance)}
+				for _, i := range d.Intervals {
+					interval := intervalJSON{Start: i.Start, Hours: i.Duration().Hours(), Project: i.Project, Tags: i.Tags, Note: i.Note}
+					if !i.Open() {
+						end := i.End
+						interval.End = &end
+					}
+					day.Intervals = append(day.Intervals, interval)
+				}
+				for _, a := range d.Absences {
+					day.Absences = append(day.Absences, newFileAbsence(a))
+				}
+				wj.Days = append(wj.Days, day)
+			}
+			group.Weeks = append(group.Weeks, wj)
+		}
+		doc.Groups = append(doc.Groups, group)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+// csvFormatter writes a row for every interval and absence. Grouped reports
+// start each row with the name of the group.
+type csvFormatter struct{}
+
+func (csvFormatter) Format(w io.Writer, r Report) error {
+	out := csv.NewWriter(w)
+
+	header := []string{"date", "start", "end", "hours", "project", "tags", "note", "absence"}
+	if r.GroupBy != GroupByNone {
+		header = append([]string{"group"}, header...)
+	}
+	if err := out.Write(header); err != nil {
+		return err
+	}
+
+	for _, g := range r.Groups {
+		for _, d := range g.Days() {
+			var rows [][]string
+			for _, i := range d.Intervals {
+				end := ""
+				if !i.Open() {
+					end = i.End.Format("15:04")
+				}
+				rows = append(rows, []string{d.Date.Format(isoDate), i.Start.Format("15:04"), end, fmt.Sprintf("%.2f", i.Duration().Hours()), i.Project, strings.Join(i.Tags, ","), i.Note, ""})
+			}
+			for _, a := range d.Absences {
+				kind := string(a.Type)
+				if a.Half {
+					kind = "half " + kind
+				}
+				rows = append(rows, []string{d.Date.Format(isoDate), "", "", "", "", "", a.Note, kind})
+			}
+
+			for _, row := range rows {
+				if r.GroupBy != GroupByNone {
+					row = append([]string{g.Name}, row...)
+				}
+				if err := out.Write(row); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	out.Flush()
+	return out.Error()
+}
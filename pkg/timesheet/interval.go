@@ -0,0 +1,134 @@
+package timesheet
+
+import (
+	"errors"
+	"time"
+)
+
+// Interval is a tracked period of time. An interval without an end time is
+// still running.
+type Interval struct {
+	Start   time.Time
+	End     time.Time // Zero while the interval is open.
+	Project string    // Optional project the time was spent on.
+	Tags    []string  // Optional tags describing the work.
+	Note    string    // Optional free text about the work.
+	UID     string    // Optional identifier of the calendar event the interval was imported from.
+}
+
+// Open reports whether the interval has not been stopped yet.
+func (i Interval) Open() bool {
+	return i.End.IsZero()
+}
+
+// Duration returns the length of the interval. Open intervals have no
+// duration.
+func (i Interval) Duration() time.Duration {
+	if i.Open() {
+		return 0
+	}
+	return i.End.Sub(i.Start)
+}
+
+// Overlaps reports whether the interval covers any time between from and
+// to. Open intervals cover all time after their start.
+func (i Interval) Overlaps(from, to time.Time) bool {
+	if !i.Start.Before(to) {
+		return false
+	}
+	return i.Open() || i.End.After(from)
+}
+
+// HasTag reports whether the interval is tagged with the given tag.
+func (i Interval) HasTag(tag string) bool {
+	for _, t := range i.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// AddNote appends the note to the existing note of the interval.
+func (i *Interval) AddNote(note string) {
+	switch {
+	case note == "":
+	case i.Note == "":
+		i.Note = note
+	default:
+		i.Note += "; " + note
+	}
+}
+
+// Round returns the interval with start and end rounded to the given
+// duration.
+func (i Interval) Round(d time.Duration) Interval {
+	i.Start = i.Start.Round(d)
+	if !i.Open() {
+		i.End = i.End.Round(d)
+	}
+	return i
+}
+
+// In returns the interval with start and end in the given location.
+func (i Interval) In(loc *time.Location) Interval {
+	i.Start = i.Start.In(loc)
+	if !i.Open() {
+		i.End = i.End.In(loc)
+	}
+	return i
+}
+
+// SplitDays splits the interval at midnight into one interval per calendar
+// day. Open intervals are not split.
+func (i Interval) SplitDays() []Interval {
+	var days []Interval
+
+	for !i.Open() {
+		year, month, day := i.Start.Date()
+		midnight := time.Date(year, month, day+1, 0, 0, 0, 0, i.Start.Location())
+		if !i.End.After(midnight) {
+			break
+		}
+
+		d := i
+		d.End = midnight
+		days = append(days, d)
+
+		i.Start = midnight
+	}
+
+	return append(days, i)
+}
+
+// ParseInterval parses a "start-end" value with the given parsers of the
+// start and end time. Without end time the interval is open. As the time
+// formats and UTC offsets may contain dashes themselves every dash is tried
+// as separator.
+func ParseInterval(value string, parseStart, parseEnd func(string) (time.Time, error)) (Interval, error) {
+	invalid := errors.New("invalid interval")
+	for i, c := range value {
+		if c != '-' {
+			continue
+		}
+
+		start, err := parseStart(value[:i])
+		if err != nil {
+			continue
+		}
+		if i == len(value)-1 {
+			return Interval{Start: start}, nil
+		}
+		end, err := parseEnd(value[i+1:])
+		if err != nil {
+			continue
+		}
+		if end.Before(start) {
+			invalid = errors.New("end time is earlier as start time")
+			continue
+		}
+		return Interval{Start: start, End: end}, nil
+	}
+
+	return Interval{}, invalid
+}
@@ -0,0 +1,186 @@
+package timesheet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestIntervalDuration(t *testing.T) {
+	start := time.Date(2018, time.September, 1, 10, 0, 0, 0, time.Now().Location())
+
+	tests := []struct {
+		name     string
+		interval Interval
+		want     time.Duration
+	}{
+		{
+			name:     "closed",
+			interval: Interval{Start: start, End: start.Add(90 * time.Minute)},
+			want:     90 * time.Minute,
+		},
+		{
+			name:     "open",
+			interval: Interval{Start: start},
+			want:     0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.interval.Duration(); got != tt.want {
+				t.Errorf("Interval.Duration() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIntervalSplitDays(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("time zone database not available: %s", err)
+	}
+
+	tests := []struct {
+		name      string
+		interval  Interval
+		want      []Interval
+		durations []time.Duration
+	}{
+		{
+			name: "same day",
+			interval: Interval{
+				Start: time.Date(2018, time.September, 1, 8, 0, 0, 0, berlin),
+				End:   time.Date(2018, time.September, 1, 12, 0, 0, 0, berlin),
+			},
+			want: []Interval{
+				{
+					Start: time.Date(2018, time.September, 1, 8, 0, 0, 0, berlin),
+					End:   time.Date(2018, time.September, 1, 12, 0, 0, 0, berlin),
+				},
+			},
+			durations: []time.Duration{4 * time.Hour},
+		},
+		{
+			name: "open",
+			interval: Interval{
+				Start: time.Date(2018, time.September, 1, 22, 0, 0, 0, berlin),
+			},
+			want: []Interval{
+				{Start: time.Date(2018, time.September, 1, 22, 0, 0, 0, berlin)},
+			},
+			durations: []time.Duration{0},
+		},
+		{
+			name: "ends at midnight",
+			interval: Interval{
+				Start: time.Date(2018, time.September, 1, 22, 0, 0, 0, berlin),
+				End:   time.Date(2018, time.September, 2, 0, 0, 0, 0, berlin),
+			},
+			want: []Interval{
+				{
+					Start: time.Date(2018, time.September, 1, 22, 0, 0, 0, berlin),
+					End:   time.Date(2018, time.September, 2, 0, 0, 0, 0, berlin),
+				},
+			},
+			durations: []time.Duration{2 * time.Hour},
+		},
+		{
+			name: "month boundary",
+			interval: Interval{
+				Start:   time.Date(2018, time.August, 31, 22, 0, 0, 0, berlin),
+				End:     time.Date(2018, time.September, 1, 2, 0, 0, 0, berlin),
+				Project: "acme",
+			},
+			want: []Interval{
+				{
+					Start:   time.Date(2018, time.August, 31, 22, 0, 0, 0, berlin),
+					End:     time.Date(2018, time.September, 1, 0, 0, 0, 0, berlin),
+					Project: "acme",
+				},
+				{
+					Start:   time.Date(2018, time.September, 1, 0, 0, 0, 0, berlin),
+					End:     time.Date(2018, time.September, 1, 2, 0, 0, 0, berlin),
+					Project: "acme",
+				},
+			},
+			durations: []time.Duration{2 * time.Hour, 2 * time.Hour},
+		},
+		{
+			name: "multiple days",
+			interval: Interval{
+				Start: time.Date(2018, time.December, 31, 20, 0, 0, 0, berlin),
+				End:   time.Date(2019, time.January, 2, 4, 0, 0, 0, berlin),
+			},
+			want: []Interval{
+				{
+					Start: time.Date(2018, time.December, 31, 20, 0, 0, 0, berlin),
+					End:   time.Date(2019, time.January, 1, 0, 0, 0, 0, berlin),
+				},
+				{
+					Start: time.Date(2019, time.January, 1, 0, 0, 0, 0, berlin),
+					End:   time.Date(2019, time.January, 2, 0, 0, 0, 0, berlin),
+				},
+				{
+					Start: time.Date(2019, time.January, 2, 0, 0, 0, 0, berlin),
+					End:   time.Date(2019, time.January, 2, 4, 0, 0, 0, berlin),
+				},
+			},
+			durations: []time.Duration{4 * time.Hour, 24 * time.Hour, 4 * time.Hour},
+		},
+		{
+			name: "spring forward",
+			interval: Interval{
+				Start: time.Date(2018, time.March, 24, 22, 0, 0, 0, berlin),
+				End:   time.Date(2018, time.March, 25, 6, 0, 0, 0, berlin),
+			},
+			want: []Interval{
+				{
+					Start: time.Date(2018, time.March, 24, 22, 0, 0, 0, berlin),
+					End:   time.Date(2018, time.March, 25, 0, 0, 0, 0, berlin),
+				},
+				{
+					Start: time.Date(2018, time.March, 25, 0, 0, 0, 0, berlin),
+					End:   time.Date(2018, time.March, 25, 6, 0, 0, 0, berlin),
+				},
+			},
+			durations: []time.Duration{2 * time.Hour, 5 * time.Hour},
+		},
+		{
+			name: "fall back",
+			interval: Interval{
+				Start: time.Date(2018, time.October, 27, 22, 0, 0, 0, berlin),
+				End:   time.Date(2018, time.October, 28, 6, 0, 0, 0, berlin),
+			},
+			want: []Interval{
+				{
+					Start: time.Date(2018, time.October, 27, 22, 0, 0, 0, berlin),
+					End:   time.Date(2018, time.October, 28, 0, 0, 0, 0, berlin),
+				},
+				{
+					Start: time.Date(2018, time.October, 28, 0, 0, 0, 0, berlin),
+					End:   time.Date(2018, time.October, 28, 6, 0, 0, 0, berlin),
+				},
+			},
+			durations: []time.Duration{2 * time.Hour, 7 * time.Hour},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.interval.SplitDays()
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Interval.SplitDays() differs: (-want +got)\n%s", diff)
+			}
+
+			var durations []time.Duration
+			for _, d := range got {
+				durations = append(durations, d.Duration())
+			}
+			if diff := cmp.Diff(tt.durations, durations); diff != "" {
+				t.Errorf("Interval.SplitDays() durations differ: (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
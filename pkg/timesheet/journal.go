@@ -0,0 +1,264 @@
+package timesheet
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// journalSnapshot is the type of journal records containing the complete
+// timesheet.
+const journalSnapshot EventType = "snapshot"
+
+// JournalStore stores the timesheet as append-only journal. Every change is
+// appended as single JSON line which makes writes cheap and keeps a record
+// of all corrections. Loading replays the journal from the last snapshot.
+type JournalStore struct {
+	Path       string
+	DateFormat string // Format used to write and parse dates.
+	TimeFormat string // Format used to write and parse times.
+	Backups    int    // Number of previous versions kept when compacting.
+}
+
+// journalRecord is a line in the journal.
+type journalRecord struct {
+	Seq       int               `json:"seq"`
+	Time      time.Time         `json:"time"`
+	Type      EventType         `json:"type"`
+	Index     int               `json:"index,omitempty"`
+	Interval  *journalInterval  `json:"interval,omitempty"`
+	Intervals []journalInterval `json:"intervals,omitempty"`
+	Absence   *fileAbsence      `json:"absence,omitempty"`
+	Absences  []fileAbsence     `json:"absences,omitempty"`
+}
+
+type journalInterval struct {
+	Start   *time.Time `json:"start,omitempty"`
+	End     *time.Time `json:"end,omitempty"`
+	Project string     `json:"project,omitempty"`
+	Tags    []string   `json:"tags,omitempty"`
+	Note    string     `json:"note,omitempty"`
+	UID     string     `json:"uid,omitempty"`
+}
+
+// NewJournalStore returns a store for the journal at path.
+func NewJournalStore(path, dateFormat, timeFormat string) *JournalStore {
+	return &JournalStore{
+		Path:       path,
+		DateFormat: dateFormat,
+		TimeFormat: timeFormat,
+	}
+}
+
+// Load replays the journal. A missing file is an empty timesheet.
+func (j *JournalStore) Load() (*Sheet, error) {
+	sheet, _, err := j.replay()
+	return sheet, err
+}
+
+// Save appends a snapshot of the timesheet to the journal.
+func (j *JournalStore) Save(sheet *Sheet) error {
+	_, seq, err := j.replay()
+	if err != nil {
+		return err
+	}
+
+	return j.append(snapshotRecord(seq+1, sheet))
+}
+
+// Append validates the events against the journal and appends them with a
+// single write.
+func (j *JournalStore) Append(events ...Event) error {
+	sheet, seq, err := j.replay()
+	if err != nil {
+		return err
+	}
+
+	var records []journalRecord
+	for _, e := range events {
+		if err := sheet.Apply(e); err != nil {
+			return err
+		}
+
+		seq++
+		record := journalRecord{
+			Seq:   seq,
+			Time:  time.Now(),
+			Type:  e.Type,
+			Index: e.Index,
+		}
+		switch e.Type {
+		case EventAddAbsence:
+			absence := newFileAbsence(e.Absence)
+			record.Absence = &absence
+		case EventDeleteAbsence:
+		default:
+			interval := newJournalInterval(e.Interval)
+			record.Interval = &interval
+		}
+		records = append(records, record)
+	}
+
+	return j.append(records...)
+}
+
+// Compact replaces the journal with a single snapshot of the timesheet.
+func (j *JournalStore) Compact() error {
+	sheet, seq, err := j.replay()
+	if err != nil {
+		return err
+	}
+
+	return writeFile(j.Path, j.Backups, func(w io.Writer) error {
+		return json.NewEncoder(w).Encode(snapshotRecord(seq+1, sheet))
+	})
+}
+
+// replay applies all records of the journal and returns the resulting
+// timesheet and the last sequence number.
+func (j *JournalStore) replay() (*Sheet, int, error) {
+	sheet := NewSheet(j.DateFormat, j.TimeFormat, nil)
+
+	file, err := os.Open(j.Path)
+	if os.IsNotExist(err) {
+		return sheet, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	defer file.Close()
+
+	var seq int
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var r journalRecord
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			return nil, 0, fmt.Errorf("%s:%d: %s", j.Path, line, err)
+		}
+
+		if r.Type == journalSnapshot {
+			var intervals []Interval
+			for _, i := range r.Intervals {
+				intervals = append(intervals, i.interval())
+			}
+			sheet = NewSheet(j.DateFormat, j.TimeFormat, intervals)
+			if sheet.absences, err = parseAbsences(r.Absences); err != nil {
+				return nil, 0, fmt.Errorf("%s:%d: %s", j.Path, line, err)
+			}
+		} else {
+			e := Event{Type: r.Type, Index: r.Index}
+			if r.Interval != nil {
+				e.Interval = r.Interval.interval()
+			}
+			if r.Absence != nil {
+				if e.Absence, err = r.Absence.absence(); err != nil {
+					return nil, 0, fmt.Errorf("%s:%d: %s", j.Path, line, err)
+				}
+			}
+			if err := sheet.Apply(e); err != nil {
+				return nil, 0, fmt.Errorf("%s:%d: %s", j.Path, line, err)
+			}
+		}
+
+		seq = r.Seq
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return sheet, seq, nil
+}
+
+// append writes the records as new lines to the end of the journal.
+func (j *JournalStore) append(records ...journalRecord) error {
+	// encode all records first, so they are appended with one write
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, r := range records {
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+
+	file, err := os.OpenFile(j.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(buf.Bytes()); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+func snapshotRecord(seq int, sheet *Sheet) journalRecord {
+	intervals := []journalInterval{}
+	for _, i := range sheet.intervals {
+		intervals = append(intervals, newJournalInterval(i))
+	}
+
+	var absences []fileAbsence
+	for _, a := range sheet.absences {
+		absences = append(absences, newFileAbsence(a))
+	}
+
+	return journalRecord{
+		Seq:       seq,
+		Time:      time.Now(),
+		Type:      journalSnapshot,
+		Intervals: intervals,
+		Absences:  absences,
+	}
+}
+
+func newJournalInterval(i Interval) journalInterval {
+	return journalInterval{
+		Start:   timePtr(i.Start),
+		End:     timePtr(i.End),
+		Project: i.Project,
+		Tags:    i.Tags,
+		Note:    i.Note,
+		UID:     i.UID,
+	}
+}
+
+// interval returns the interval of the entry. The times keep their UTC
+// offset.
+func (ji journalInterval) interval() Interval {
+	i := Interval{
+		Project: ji.Project,
+		Tags:    ji.Tags,
+		Note:    ji.Note,
+		UID:     ji.UID,
+	}
+	if ji.Start != nil {
+		i.Start = *ji.Start
+	}
+	if ji.End != nil {
+		i.End = *ji.End
+	}
+	return i
+}
+
+// timePtr returns nil for the zero time so it is omitted in the journal.
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
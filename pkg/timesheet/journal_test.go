@@ -0,0 +1,112 @@
+package timesheet
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestJournalStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "tt.journal")
+	store := NewJournalStore(path, "02.01.2006", "15:04")
+
+	start := time.Date(2018, time.September, 1, 8, 0, 0, 0, time.Now().Location())
+	events := []Event{
+		{Type: EventStart, Interval: Interval{Start: start, Project: "acme"}},
+		{Type: EventStop, Interval: Interval{End: start.Add(4 * time.Hour), Note: "invoices"}},
+		{Type: EventStart, Interval: Interval{Start: start.Add(5 * time.Hour)}},
+		{Type: EventAdd, Interval: Interval{Start: start.Add(-time.Hour), End: start, UID: "standup@calendar.example"}},
+	}
+	for _, e := range events[:2] {
+		if err := store.Append(e); err != nil {
+			t.Fatalf("Append(%v) error = %v", e.Type, err)
+		}
+	}
+	// several events are appended as one line each
+	if err := store.Append(events[2:]...); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	want := []Interval{
+		{Start: start.Add(-time.Hour), End: start, UID: "standup@calendar.example"},
+		{Start: start, End: start.Add(4 * time.Hour), Project: "acme", Note: "invoices"},
+		{Start: start.Add(5 * time.Hour)},
+	}
+
+	if got := bytes.Count(readFile(t, path), []byte("\n")); got != len(events) {
+		t.Errorf("journal has %d lines, want %d", got, len(events))
+	}
+
+	if err := store.Compact(); err != nil {
+		t.Fatalf("Compact() error = %v", err)
+	}
+	if got := bytes.Count(readFile(t, path), []byte("\n")); got != 1 {
+		t.Errorf("compacted journal has %d lines, want 1", got)
+	}
+
+	sheet, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if diff := cmp.Diff(want, sheet.Intervals()); diff != "" {
+		t.Errorf("Load() after Compact() differs: (-want +got)\n%s", diff)
+	}
+
+	if err := store.Append(Event{Type: EventStop, Interval: Interval{End: start.Add(6 * time.Hour)}}); err != nil {
+		t.Fatalf("Append() after Compact() error = %v", err)
+	}
+	_, seq, err := store.replay()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seq != len(events)+2 {
+		t.Errorf("sequence number = %d, want %d", seq, len(events)+2)
+	}
+}
+
+func TestJournalStoreInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		journal string
+	}{
+		{
+			name:    "invalid json",
+			journal: `{"seq":1,"type":"start"` + "\n",
+		},
+		{
+			name: "invalid event",
+			journal: `{"seq":1,"time":"2018-09-01T08:00:00Z","type":"start","interval":{"start":"2018-09-01T08:00:00Z"}}` + "\n" +
+				`{"seq":2,"time":"2018-09-01T08:00:00Z","type":"start","interval":{"start":"2018-09-01T09:00:00Z"}}` + "\n",
+		},
+		{
+			name:    "unknown event",
+			journal: `{"seq":1,"time":"2018-09-01T08:00:00Z","type":"foo"}` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := ioutil.TempFile("", "tt")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.Remove(file.Name())
+			file.WriteString(tt.journal)
+			file.Close()
+
+			if _, err := NewJournalStore(file.Name(), "02.01.2006", "15:04").Load(); err == nil {
+				t.Errorf("Load() error = nil, want error")
+			}
+		})
+	}
+}
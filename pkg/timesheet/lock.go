@@ -0,0 +1,45 @@
+package timesheet
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// errLocked is returned by tryLock if the lock is held by another process.
+var errLocked = errors.New("locked")
+
+// Lock is an advisory lock on a data file shared by all processes using the
+// same file.
+type Lock struct {
+	unlock func() error
+}
+
+// LockFile acquires the lock for the data file at path. If another process
+// holds the lock it waits up to timeout for it to be released.
+//
+// The lock is held on the separate file "path.lock" as the data file itself
+// is replaced on every save.
+func LockFile(path string, timeout time.Duration) (*Lock, error) {
+	lockPath := path + ".lock"
+	deadline := time.Now().Add(timeout)
+
+	for {
+		unlock, err := tryLock(lockPath)
+		if err == nil {
+			return &Lock{unlock: unlock}, nil
+		}
+		if err != errLocked {
+			return nil, err
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%s is locked by another process, gave up after %s", path, timeout)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// Unlock releases the lock.
+func (l *Lock) Unlock() error {
+	return l.unlock()
+}
@@ -0,0 +1,45 @@
+package timesheet
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLockFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "tt.json")
+
+	lock, err := LockFile(path, time.Second)
+	if err != nil {
+		t.Fatalf("LockFile() error = %v", err)
+	}
+
+	if _, err := LockFile(path, 50*time.Millisecond); err == nil {
+		t.Fatalf("LockFile() of locked file succeeded")
+	}
+
+	released := make(chan error)
+	go func() {
+		lock, err := LockFile(path, 5*time.Second)
+		if err == nil {
+			err = lock.Unlock()
+		}
+		released <- err
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	if err := lock.Unlock(); err != nil {
+		t.Fatalf("Unlock() error = %v", err)
+	}
+	if err := <-released; err != nil {
+		t.Errorf("LockFile() after Unlock() error = %v", err)
+	}
+}
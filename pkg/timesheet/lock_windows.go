@@ -0,0 +1,28 @@
+package timesheet
+
+import (
+	"syscall"
+)
+
+// errorSharingViolation is returned by CreateFile if another process has
+// the file open.
+const errorSharingViolation syscall.Errno = 32
+
+func tryLock(path string) (func() error, error) {
+	name, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// opening the file without sharing denies access to all other
+	// processes until it is closed
+	h, err := syscall.CreateFile(name, syscall.GENERIC_READ|syscall.GENERIC_WRITE, 0, nil, syscall.OPEN_ALWAYS, syscall.FILE_ATTRIBUTE_NORMAL, 0)
+	if err == errorSharingViolation {
+		return nil, errLocked
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return func() error { return syscall.CloseHandle(h) }, nil
+}
@@ -1,58 +1,358 @@
 package timesheet
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"sort"
 	"time"
 )
 
-type dateTimes map[string][]string
+// dateIntervals maps a date to the intervals started on that day.
+type dateIntervals map[string][]entry
 
-func unmarshal(r io.Reader, dateFormat, timeFormat string) ([]time.Time, error) {
-	var dt dateTimes
+// entry is a single interval in the data file. Intervals without further
+// details are written as "start-end" or "start-" while they are open,
+// otherwise as object.
+//
+// Older files contain bare times which are paired up as start and end
+// times in the order they appear.
+type entry struct {
+	value string
 
-	dec := json.NewDecoder(r)
-	err := dec.Decode(&dt)
-	if err != nil && err != io.EOF {
-		return nil, err
+	Start   string   `json:"start"`
+	End     string   `json:"end,omitempty"`
+	Project string   `json:"project,omitempty"`
+	Tags    []string `json:"tags,omitempty"`
+	Note    string   `json:"note,omitempty"`
+	UID     string   `json:"uid,omitempty"`
+}
+
+// plainEntry has the fields of entry without its JSON methods.
+type plainEntry entry
+
+func (e *entry) UnmarshalJSON(b []byte) error {
+	if len(b) > 0 && b[0] == '"' {
+		return json.Unmarshal(b, &e.value)
+	}
+	return json.Unmarshal(b, (*plainEntry)(e))
+}
+
+func (e entry) MarshalJSON() ([]byte, error) {
+	if e.Project == "" && len(e.Tags) == 0 && e.Note == "" && e.UID == "" {
+		return json.Marshal(e.Start + "-" + e.End)
 	}
+	return json.Marshal(plainEntry(e))
+}
+
+// version of the data file format. Files without version are from older
+// releases and store dates and times in the formats of the user. Version 2
+// stores times without UTC offset, version 3 has no absences.
+const version = 4
+
+// intervalsVersion is written for files without absences, so releases
+// which don't know about absences can still read them.
+const intervalsVersion = 3
+
+// Canonical formats of the data file.
+const (
+	fileDate        = "2006-01-02"
+	fileTime        = "15:04"
+	filePreciseTime = "15:04:05.999999999"
+	fileOffset      = "Z07:00"
+)
 
-	dateTimeFormat := fmt.Sprintf("%s %s", dateFormat, timeFormat)
+// Formats of older data files written with the default formats.
+const (
+	legacyDate = "02.01.2006"
+	legacyTime = "15:04"
+)
+
+// file is the content of a versioned data file.
+type file struct {
+	Version  int           `json:"version"`
+	Days     dateIntervals `json:"days"`
+	Absences []fileAbsence `json:"absences,omitempty"`
+}
 
-	loc := time.Now().Location()
+// fileAbsence is an absence in the data file.
+type fileAbsence struct {
+	Date string      `json:"date"`
+	Type AbsenceType `json:"type"`
+	Half bool        `json:"half,omitempty"`
+	Note string      `json:"note,omitempty"`
+}
+
+func newFileAbsence(a Absence) fileAbsence {
+	return fileAbsence{
+		Date: a.Date.Format(fileDate),
+		Type: a.Type,
+		Half: a.Half,
+		Note: a.Note,
+	}
+}
+
+// absence parses the date as calendar date.
+func (fa fileAbsence) absence() (Absence, error) {
+	date, err := time.Parse(fileDate, fa.Date)
+	if err != nil {
+		return Absence{}, err
+	}
+	if !validAbsenceType(fa.Type) {
+		return Absence{}, fmt.Errorf("%s: unknown absence type '%s'", fa.Date, fa.Type)
+	}
+	return Absence{Date: date, Type: fa.Type, Half: fa.Half, Note: fa.Note}, nil
+}
 
-	var times []time.Time
-	for dateStr, timeStrs := range dt {
-		for _, t := range timeStrs {
-			dateTime := fmt.Sprintf("%s %s", dateStr, t)
-			tm, err := time.ParseInLocation(dateTimeFormat, dateTime, loc)
+// parseAbsences parses the absences of the data file ordered by date.
+func parseAbsences(fas []fileAbsence) ([]Absence, error) {
+	var absences []Absence
+	for _, fa := range fas {
+		a, err := fa.absence()
+		if err != nil {
+			return nil, err
+		}
+		absences = append(absences, a)
+	}
+	sort.SliceStable(absences, func(i, j int) bool { return absences[i].Date.Before(absences[j].Date) })
+	return absences, nil
+}
+
+// unmarshal reads a data file and reports whether it has the format of an
+// older release. Files without version are read with the given formats and
+// as fallback with the former default formats.
+func unmarshal(r io.Reader, dateFormat, timeFormat string) ([]Interval, []Absence, bool, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	var header struct {
+		Version *int `json:"version"`
+	}
+	if len(bytes.TrimSpace(data)) != 0 {
+		if err := json.Unmarshal(data, &header); err != nil {
+			return nil, nil, false, err
+		}
+	}
+
+	if header.Version != nil {
+		if *header.Version < 2 || *header.Version > version {
+			return nil, nil, false, fmt.Errorf("unsupported data file version %d", *header.Version)
+		}
+		var f file
+		if err := json.Unmarshal(data, &f); err != nil {
+			return nil, nil, false, err
+		}
+		intervals, err := parseDays(f.Days, canonicalFormats())
+		if err != nil {
+			return nil, nil, false, err
+		}
+		absences, err := parseAbsences(f.Absences)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		return intervals, absences, f.Version < intervalsVersion, nil
+	}
+
+	var di dateIntervals
+	if len(bytes.TrimSpace(data)) != 0 {
+		if err := json.Unmarshal(data, &di); err != nil {
+			return nil, nil, false, err
+		}
+	}
+	intervals, err := parseDays(di, userFormats(dateFormat, timeFormat))
+	if err != nil && (dateFormat != legacyDate || timeFormat != legacyTime) {
+		if legacy, legacyErr := parseDays(di, userFormats(legacyDate, legacyTime)); legacyErr == nil {
+			return legacy, nil, len(di) != 0, nil
+		}
+	}
+	return intervals, nil, err == nil && len(di) != 0, err
+}
+
+// parseDays parses the entries of all days.
+func parseDays(di dateIntervals, f formats) ([]Interval, error) {
+	var intervals []Interval
+	for dateStr, entries := range di {
+		open := -1
+		for _, e := range entries {
+			if e.value == "" {
+				interval, err := parseEntry(dateStr, e, f)
+				if err != nil {
+					return nil, err
+				}
+				intervals = append(intervals, interval)
+				open = -1
+				continue
+			}
+
+			// legacy format: a single time, alternating start and end
+			if tm, err := f.parse(dateStr, e.value); err == nil {
+				if open < 0 {
+					intervals = append(intervals, Interval{Start: tm})
+					open = len(intervals) - 1
+				} else {
+					intervals[open].End = tm
+					open = -1
+				}
+				continue
+			}
+
+			interval, err := parseInterval(dateStr, e.value, f)
 			if err != nil {
 				return nil, err
 			}
-			times = append(times, tm)
+			intervals = append(intervals, interval)
+			open = -1
+		}
+	}
+
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i].Start.Before(intervals[j].Start) })
+
+	return intervals, nil
+}
+
+// formats parses the times in the data file.
+type formats struct {
+	date      string   // Layout of the dates.
+	times     []string // Layouts of the times, tried in order.
+	separator string   // Separates date and time of end times on another day.
+	loc       *time.Location
+}
+
+func canonicalFormats() formats {
+	return formats{
+		date:      fileDate,
+		times:     []string{fileTime + fileOffset, filePreciseTime + fileOffset, fileTime, filePreciseTime},
+		separator: "T",
+		loc:       time.Now().Location(),
+	}
+}
+
+func userFormats(dateFormat, timeFormat string) formats {
+	return formats{
+		date:      dateFormat,
+		times:     []string{timeFormat},
+		separator: " ",
+		loc:       time.Now().Location(),
+	}
+}
+
+// parse parses a time on the given date. Times without UTC offset are in
+// the location of the formats, the others keep their offset.
+func (f formats) parse(date, t string) (time.Time, error) {
+	var err error
+	for _, layout := range f.times {
+		var tm time.Time
+		if tm, err = time.ParseInLocation(f.date+" "+layout, date+" "+t, f.loc); err == nil {
+			return tm, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+// parseEnd parses an end time. Intervals crossing midnight store their end
+// with date. As the formats may contain the separator themselves every
+// occurrence is tried.
+func (f formats) parseEnd(date, t string) (time.Time, error) {
+	end, err := f.parse(date, t)
+	if err == nil {
+		return end, nil
+	}
+	for i := 0; i+len(f.separator) <= len(t); i++ {
+		if t[i:i+len(f.separator)] != f.separator {
+			continue
+		}
+		if end, dateErr := f.parse(t[:i], t[i+len(f.separator):]); dateErr == nil {
+			return end, nil
 		}
 	}
+	return time.Time{}, err
+}
 
-	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+// parseEntry parses an interval written as object.
+func parseEntry(date string, e entry, f formats) (Interval, error) {
+	start, err := f.parse(date, e.Start)
+	if err != nil {
+		return Interval{}, err
+	}
 
-	return times, nil
+	interval := Interval{
+		Start:   start,
+		Project: e.Project,
+		Tags:    e.Tags,
+		Note:    e.Note,
+		UID:     e.UID,
+	}
+
+	if e.End != "" {
+		interval.End, err = f.parseEnd(date, e.End)
+		if err != nil {
+			return Interval{}, err
+		}
+		if interval.End.Before(start) {
+			return Interval{}, fmt.Errorf("%s %s-%s: end time is earlier as start time", date, e.Start, e.End)
+		}
+	}
+
+	return interval, nil
 }
 
-func marshal(w io.Writer, times []time.Time, dateFormat, timeFormat string) error {
-	dt := dateTimes{}
+// parseInterval parses a "start-end" value on the given date.
+func parseInterval(date, value string, f formats) (Interval, error) {
+	interval, err := ParseInterval(value,
+		func(t string) (time.Time, error) { return f.parse(date, t) },
+		func(t string) (time.Time, error) { return f.parseEnd(date, t) },
+	)
+	if err != nil {
+		return Interval{}, fmt.Errorf("%s %s: %s", date, value, err)
+	}
+	return interval, nil
+}
+
+// marshal writes the intervals and absences in the canonical format.
+func marshal(w io.Writer, intervals []Interval, absences []Absence) error {
+	f := file{Version: intervalsVersion, Days: dateIntervals{}}
+	for _, a := range absences {
+		f.Version = version
+		f.Absences = append(f.Absences, newFileAbsence(a))
+	}
 
-	for _, t := range times {
-		date := t.Format(dateFormat)
-		if _, exists := dt[date]; !exists {
-			dt[date] = []string{}
+	for _, i := range intervals {
+		date := i.Start.Format(fileDate)
+		if _, exists := f.Days[date]; !exists {
+			f.Days[date] = []entry{}
 		}
-		dt[date] = append(dt[date], t.Format(timeFormat))
+		e := entry{
+			Start:   formatFileTime(i.Start),
+			Project: i.Project,
+			Tags:    i.Tags,
+			Note:    i.Note,
+			UID:     i.UID,
+		}
+		switch {
+		case i.Open():
+		case sameDate(i.Start, i.End):
+			e.End = formatFileTime(i.End)
+		default:
+			e.End = i.End.Format(fileDate) + "T" + formatFileTime(i.End)
+		}
+		f.Days[date] = append(f.Days[date], e)
 	}
 
 	enc := json.NewEncoder(w)
 	enc.SetIndent("", "  ")
 
-	return enc.Encode(dt)
+	return enc.Encode(f)
+}
+
+// formatFileTime writes the time with its UTC offset and omits seconds
+// unless the time has any.
+func formatFileTime(t time.Time) string {
+	if t.Second() == 0 && t.Nanosecond() == 0 {
+		return t.Format(fileTime + fileOffset)
+	}
+	return t.Format(filePreciseTime + fileOffset)
 }
@@ -13,28 +13,43 @@ import (
 var marshalTestCases = []struct {
 	description string
 	fixture     string
-	times       []time.Time
+	legacy      string // Same intervals in the format of older releases.
+	intervals   []Interval
 	wantErr     bool
 	skipMarshal bool
 }{
 	{
 		description: "invalid json",
 		fixture:     "testdata/invalid.json",
-		times:       nil,
+		intervals:   nil,
 		wantErr:     true,
 		skipMarshal: true,
 	},
 	{
 		description: "invalid date",
-		fixture:     "testdata/invalid_date.json",
-		times:       nil,
+		fixture:     "testdata/legacy/invalid_date.json",
+		intervals:   nil,
 		wantErr:     true,
 		skipMarshal: true,
 	},
 	{
 		description: "invalid time",
-		fixture:     "testdata/invalid_time.json",
-		times:       nil,
+		fixture:     "testdata/legacy/invalid_time.json",
+		intervals:   nil,
+		wantErr:     true,
+		skipMarshal: true,
+	},
+	{
+		description: "invalid interval",
+		fixture:     "testdata/legacy/invalid_interval.json",
+		intervals:   nil,
+		wantErr:     true,
+		skipMarshal: true,
+	},
+	{
+		description: "unsupported version",
+		fixture:     "testdata/unsupported_version.json",
+		intervals:   nil,
 		wantErr:     true,
 		skipMarshal: true,
 	},
@@ -42,53 +57,161 @@ var marshalTestCases = []struct {
 		description: "empty file",
 		fixture:     "testdata/empty.json",
 		skipMarshal: true,
-		times:       nil,
+		intervals:   nil,
 	},
 	{
 		description: "empty json",
 		fixture:     "testdata/empty_json.json",
-		times:       nil,
+		legacy:      "testdata/legacy/empty_json.json",
+		intervals:   nil,
 	},
 	{
 		description: "empty day",
-		fixture:     "testdata/empty_day.json",
-		times:       nil,
+		fixture:     "testdata/legacy/empty_day.json",
+		intervals:   nil,
 		skipMarshal: true,
 	},
 	{
 		description: "one day only start",
 		fixture:     "testdata/one_day_only_start.json",
-		times: []time.Time{
-			time.Date(2018, time.September, 1, 10, 0, 0, 0, time.Now().Location()),
+		legacy:      "testdata/legacy/one_day_only_start.json",
+		intervals: []Interval{
+			{Start: time.Date(2018, time.September, 1, 10, 0, 0, 0, cest)},
 		},
 	},
 	{
 		description: "one day start/end",
 		fixture:     "testdata/one_day_start_end.json",
-		times: []time.Time{
-			time.Date(2018, time.September, 1, 10, 0, 0, 0, time.Now().Location()),
-			time.Date(2018, time.September, 1, 12, 0, 0, 0, time.Now().Location()),
+		legacy:      "testdata/legacy/one_day_start_end.json",
+		intervals: []Interval{
+			{
+				Start: time.Date(2018, time.September, 1, 10, 0, 0, 0, cest),
+				End:   time.Date(2018, time.September, 1, 12, 0, 0, 0, cest),
+			},
 		},
 	},
 	{
 		description: "one day start/end start",
 		fixture:     "testdata/one_day_start_end_start.json",
-		times: []time.Time{
-			time.Date(2018, time.September, 1, 10, 0, 0, 0, time.Now().Location()),
-			time.Date(2018, time.September, 1, 12, 0, 0, 0, time.Now().Location()),
-			time.Date(2018, time.September, 1, 13, 0, 0, 0, time.Now().Location()),
+		legacy:      "testdata/legacy/one_day_start_end_start.json",
+		intervals: []Interval{
+			{
+				Start: time.Date(2018, time.September, 1, 10, 0, 0, 0, cest),
+				End:   time.Date(2018, time.September, 1, 12, 0, 0, 0, cest),
+			},
+			{Start: time.Date(2018, time.September, 1, 13, 0, 0, 0, cest)},
 		},
 	},
 	{
 		description: "multiple days",
 		fixture:     "testdata/multiple_days.json",
-		times: []time.Time{
-			time.Date(2018, time.September, 1, 10, 0, 0, 0, time.Now().Location()),
-			time.Date(2018, time.September, 1, 12, 0, 0, 0, time.Now().Location()),
-			time.Date(2018, time.September, 2, 8, 0, 0, 0, time.Now().Location()),
+		legacy:      "testdata/legacy/multiple_days.json",
+		intervals: []Interval{
+			{
+				Start: time.Date(2018, time.September, 1, 10, 0, 0, 0, cest),
+				End:   time.Date(2018, time.September, 1, 12, 0, 0, 0, cest),
+			},
+			{Start: time.Date(2018, time.September, 2, 8, 0, 0, 0, cest)},
 		},
 	},
-}
+	{
+		description: "legacy times",
+		fixture:     "testdata/legacy/legacy_times.json",
+		skipMarshal: true,
+		intervals: []Interval{
+			{
+				Start: time.Date(2018, time.September, 1, 10, 0, 0, 0, cest),
+				End:   time.Date(2018, time.September, 1, 12, 0, 0, 0, cest),
+			},
+			{Start: time.Date(2018, time.September, 1, 13, 0, 0, 0, cest)},
+			{Start: time.Date(2018, time.September, 2, 8, 0, 0, 0, cest)},
+		},
+	},
+	{
+		description: "stray time",
+		fixture:     "testdata/legacy/stray_time.json",
+		skipMarshal: true,
+		intervals: []Interval{
+			{
+				Start: time.Date(2018, time.September, 1, 10, 0, 0, 0, cest),
+				End:   time.Date(2018, time.September, 1, 12, 0, 0, 0, cest),
+			},
+			{Start: time.Date(2018, time.September, 1, 12, 30, 0, 0, cest)},
+			{
+				Start: time.Date(2018, time.September, 1, 13, 0, 0, 0, cest),
+				End:   time.Date(2018, time.September, 1, 17, 0, 0, 0, cest),
+			},
+		},
+	},
+	{
+		description: "project, tags and note",
+		fixture:     "testdata/details.json",
+		legacy:      "testdata/legacy/details.json",
+		intervals: []Interval{
+			{
+				Start:   time.Date(2018, time.September, 1, 10, 0, 0, 0, cest),
+				End:     time.Date(2018, time.September, 1, 12, 0, 0, 0, cest),
+				Project: "acme",
+				Tags:    []string{"review"},
+			},
+			{
+				Start: time.Date(2018, time.September, 1, 13, 0, 0, 0, cest),
+				Note:  "fixing invoices",
+			},
+		},
+	},
+	{
+		description: "calendar uid",
+		fixture:     "testdata/uid.json",
+		intervals: []Interval{
+			{
+				Start: time.Date(2018, time.September, 3, 9, 0, 0, 0, cest),
+				End:   time.Date(2018, time.September, 3, 10, 0, 0, 0, cest),
+				UID:   "meeting-1@calendar.example",
+			},
+		},
+	},
+	{
+		description: "seconds",
+		fixture:     "testdata/seconds.json",
+		intervals: []Interval{
+			{
+				Start: time.Date(2018, time.September, 1, 10, 0, 30, 0, cest),
+				End:   time.Date(2018, time.September, 1, 12, 0, 0, 500, cest),
+			},
+			{
+				Start: time.Date(2018, time.September, 1, 23, 0, 0, 0, cest),
+				End:   time.Date(2018, time.September, 2, 1, 0, 15, 0, cest),
+			},
+		},
+	},
+	{
+		description: "time zones",
+		fixture:     "testdata/time_zones.json",
+		intervals: []Interval{
+			// recorded while travelling
+			{
+				Start: time.Date(2018, time.September, 3, 9, 0, 0, 0, time.FixedZone("EDT", -4*60*60)),
+				End:   time.Date(2018, time.September, 3, 17, 0, 0, 0, time.FixedZone("EDT", -4*60*60)),
+			},
+			// a night shift during the switch to standard time
+			{
+				Start: time.Date(2018, time.October, 27, 22, 0, 0, 0, cest),
+				End:   time.Date(2018, time.October, 28, 2, 30, 0, 0, time.FixedZone("CET", 60*60)),
+			},
+		},
+	},
+	{
+		description: "cross midnight",
+		fixture:     "testdata/cross_midnight.json",
+		legacy:      "testdata/legacy/cross_midnight.json",
+		intervals: []Interval{
+			{
+				Start: time.Date(2018, time.August, 31, 22, 0, 0, 0, cest),
+				End:   time.Date(2018, time.September, 1, 2, 0, 0, 0, cest),
+			},
+		},
+	}}
 
 func TestUnmarshal(t *testing.T) {
 	var timeFormat = "15:04"
@@ -96,25 +219,28 @@ func TestUnmarshal(t *testing.T) {
 
 	for _, tc := range marshalTestCases {
 		t.Run(tc.description, func(t *testing.T) {
-			file, _ := os.Open(tc.fixture)
+			for _, fixture := range []string{tc.fixture, tc.legacy} {
+				if fixture == "" {
+					continue
+				}
+				file, _ := os.Open(fixture)
 
-			actual, err := unmarshal(file, dateFormat, timeFormat)
+				actual, _, _, err := unmarshal(file, dateFormat, timeFormat)
+				file.Close()
 
-			if (err != nil) != tc.wantErr {
-				t.Errorf("unmarshal() error = %v, wantErr %v", err, tc.wantErr)
-				return
-			}
-			if diff := cmp.Diff(tc.times, actual); diff != "" {
-				t.Errorf("unmarshal() differs: (-want +got)\n%s", diff)
+				if (err != nil) != tc.wantErr {
+					t.Errorf("unmarshal(%s) error = %v, wantErr %v", fixture, err, tc.wantErr)
+					continue
+				}
+				if diff := cmp.Diff(tc.intervals, actual); diff != "" {
+					t.Errorf("unmarshal(%s) differs: (-want +got)\n%s", fixture, diff)
+				}
 			}
 		})
 	}
 }
 
 func TestMarshal(t *testing.T) {
-	var timeFormat = "15:04"
-	var dateFormat = "02.01.2006"
-
 	for _, tc := range marshalTestCases {
 		if tc.skipMarshal {
 			continue
@@ -124,7 +250,7 @@ func TestMarshal(t *testing.T) {
 			want := readFile(t, tc.fixture)
 
 			var actual bytes.Buffer
-			marshal(&actual, tc.times, dateFormat, timeFormat)
+			marshal(&actual, tc.intervals, nil)
 
 			if diff := cmp.Diff(strings.Replace(string(want), "\r\n", "\n", -1), strings.Replace(actual.String(), "\r\n", "\n", -1)); diff != "" {
 				t.Errorf("marshal() differs: (-want +got)\n%s", diff)
@@ -145,17 +271,116 @@ func TestMarshalUnmarshal(t *testing.T) {
 		t.Run(tc.description, func(t *testing.T) {
 			var actual bytes.Buffer
 
-			marshal(&actual, tc.times, dateFormat, timeFormat)
-			times, err := unmarshal(&actual, dateFormat, timeFormat)
+			marshal(&actual, tc.intervals, nil)
+			intervals, _, _, err := unmarshal(&actual, dateFormat, timeFormat)
 
 			if err != nil {
 				t.Errorf("unmarshal(marshal()) error = %v", err)
 				return
 			}
-			if diff := cmp.Diff(tc.times, times); diff != "" {
+			if diff := cmp.Diff(tc.intervals, intervals); diff != "" {
 				t.Errorf("unmarshal(marshal()) differs: (-want +got)\n%s", diff)
 			}
 		})
 	}
+}
+
+func TestUnmarshalKeepsOffsets(t *testing.T) {
+	want := readFile(t, "testdata/time_zones.json")
+
+	intervals, _, _, err := unmarshal(bytes.NewReader(want), "02.01.2006", "15:04")
+	if err != nil {
+		t.Fatalf("unmarshal() error = %v", err)
+	}
+
+	var actual bytes.Buffer
+	marshal(&actual, intervals, nil)
+
+	if diff := cmp.Diff(strings.Replace(string(want), "\r\n", "\n", -1), strings.Replace(actual.String(), "\r\n", "\n", -1)); diff != "" {
+		t.Errorf("marshal(unmarshal()) differs: (-want +got)\n%s", diff)
+	}
+}
+
+func TestUnmarshalLegacyFormats(t *testing.T) {
+	want := []Interval{
+		{
+			Start: time.Date(2018, time.September, 1, 10, 0, 0, 0, time.Now().Location()),
+			End:   time.Date(2018, time.September, 1, 12, 0, 0, 0, time.Now().Location()),
+		},
+	}
+
+	tests := []struct {
+		name       string
+		data       string
+		dateFormat string
+		timeFormat string
+	}{
+		{
+			name:       "user formats",
+			data:       `{"2018-09-01": ["10:00:00-12:00:00"]}`,
+			dateFormat: "2006-01-02",
+			timeFormat: "15:04:05",
+		},
+		{
+			name:       "written with default formats",
+			data:       `{"01.09.2018": ["10:00-12:00"]}`,
+			dateFormat: "2006-01-02",
+			timeFormat: "3:04PM",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, legacy, err := unmarshal(strings.NewReader(tt.data), tt.dateFormat, tt.timeFormat)
+			if err != nil {
+				t.Fatalf("unmarshal() error = %v", err)
+			}
+			if !legacy {
+				t.Errorf("unmarshal() didn't report older format")
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("unmarshal() differs: (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMarshalAbsences(t *testing.T) {
+	intervals := []Interval{
+		{
+			Start: time.Date(2018, time.September, 3, 9, 0, 0, 0, cest),
+			End:   time.Date(2018, time.September, 3, 13, 0, 0, 0, cest),
+		},
+	}
+	absences := []Absence{
+		{Date: time.Date(2018, time.September, 3, 0, 0, 0, 0, time.UTC), Type: AbsenceVacation, Half: true},
+		{Date: time.Date(2018, time.October, 3, 0, 0, 0, 0, time.UTC), Type: AbsenceHoliday, Note: "German Unity Day"},
+	}
+	fixture := "testdata/absences.json"
 
+	var got bytes.Buffer
+	if err := marshal(&got, intervals, absences); err != nil {
+		t.Fatalf("marshal() error = %v", err)
+	}
+	if diff := cmp.Diff(string(readFile(t, fixture)), got.String()); diff != "" {
+		t.Errorf("marshal() differs: (-want +got)\n%s", diff)
+	}
+
+	gotIntervals, gotAbsences, legacy, err := unmarshal(bytes.NewReader(readFile(t, fixture)), "02.01.2006", "15:04")
+	if err != nil {
+		t.Fatalf("unmarshal() error = %v", err)
+	}
+	if legacy {
+		t.Errorf("unmarshal() reported older format")
+	}
+	if diff := cmp.Diff(intervals, gotIntervals); diff != "" {
+		t.Errorf("unmarshal() intervals differ: (-want +got)\n%s", diff)
+	}
+	if diff := cmp.Diff(absences, gotAbsences); diff != "" {
+		t.Errorf("unmarshal() absences differ: (-want +got)\n%s", diff)
+	}
+
+	_, _, _, err = unmarshal(strings.NewReader(`{"version": 4, "days": {}, "absences": [{"date": "2018-09-03", "type": "party"}]}`), "02.01.2006", "15:04")
+	if err == nil {
+		t.Errorf("unmarshal() of unknown absence type succeeded")
+	}
 }
@@ -3,86 +3,63 @@ package timesheet
 import (
 	"fmt"
 	"io"
-	"time"
 )
 
-func print(times []time.Time, roundTo time.Duration, dateFormat, timeFormat string, out io.Writer) {
-	for i, t := range times {
-		times[i] = t.Round(roundTo)
-	}
+var groupLabels = map[GroupBy]string{
+	GroupByProject: "Project",
+	GroupByTag:     "Tag",
+}
 
-	days := groupTimesByDay(times)
+// Formatter writes a report in an output format.
+type Formatter interface {
+	Format(w io.Writer, r Report) error
+}
 
-	if len(days) == 0 {
-		return
+// NewFormatter returns the formatter of the named output format. The text,
+// markdown, html and summary formats are built-in templates.
+func NewFormatter(format string) (Formatter, error) {
+	switch format {
+	case "", "text":
+		return NewTemplateFormatter("text", textTemplate, false)
+	case "markdown":
+		return NewTemplateFormatter(format, markdownTemplate, false)
+	case "html":
+		return NewTemplateFormatter(format, htmlTemplate, true)
+	case "summary":
+		return NewTemplateFormatter(format, summaryTemplate, false)
+	case "json":
+		return jsonFormatter{}, nil
+	case "csv":
+		return csvFormatter{}, nil
+	default:
+		return nil, fmt.Errorf("unknown format '%s'", format)
 	}
+}
 
-	var week int
-	var totalHours time.Duration
-	for _, times := range days {
-		// output newline after each week
-		_, w := times[0].ISOWeek()
-		if week > 0 && week != w {
-			fmt.Fprintln(out, "")
-		}
-		week = w
-
-		hours := calculateHours(times)
-
-		// output date and hours (ie. "01.09.2018 8.50")
-		fmt.Fprintf(out, "%s  %.2f ", times[0].Format(dateFormat), hours.Hours())
-
-		// output individual intervals (ie. "10:00-12:30 13:00-16:30")
-		for i, t := range times {
-			if i%2 == 0 {
-				fmt.Fprintf(out, " %s-", t.Format(timeFormat))
-			} else {
-				fmt.Fprintf(out, "%s", t.Format(timeFormat))
-			}
-		}
-
-		totalHours += hours
-
-		fmt.Fprintln(out, "")
+// groupName returns the name of the group as written in the output.
+func groupName(g Group) string {
+	if g.Name == "" {
+		return "(none)"
 	}
-
-	fmt.Fprintf(out, "\nTotal: %.2f\n", totalHours.Hours())
+	return g.Name
 }
 
-func groupTimesByDay(times []time.Time) [][]time.Time {
-	var days [][]time.Time
-	var dayTimes []time.Time
-	var prev time.Time
-
-	for _, t := range times {
-		if prev.IsZero() {
-			prev = t
-		}
-		if !sameDate(prev, t) {
-			days = append(days, dayTimes)
-			dayTimes = []time.Time{}
-		}
-		dayTimes = append(dayTimes, t)
-		prev = t
-	}
-	if len(dayTimes) > 0 {
-		days = append(days, dayTimes)
+func formatInterval(i Interval, timeFormat string) string {
+	if i.Open() {
+		return i.Start.Format(timeFormat) + "-"
 	}
-
-	return days
+	return i.Start.Format(timeFormat) + "-" + i.End.Format(timeFormat)
 }
 
-func calculateHours(times []time.Time) time.Duration {
-	var hours time.Duration
-
-	var start time.Time
-	for i, t := range times {
-		if i%2 == 0 {
-			start = t
-		} else {
-			hours += t.Sub(start)
-		}
+// formatAbsence returns the absence as written in the output (ie. "half
+// vacation").
+func formatAbsence(a Absence) string {
+	s := string(a.Type)
+	if a.Half {
+		s = "half " + s
 	}
-
-	return hours
+	if a.Note != "" {
+		s += " (" + a.Note + ")"
+	}
+	return s
 }
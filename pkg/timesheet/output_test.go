@@ -11,36 +11,49 @@ import (
 
 var outputTestCases = []struct {
 	description string
-	times       []time.Time
+	intervals   []Interval
 	fixture     string
 }{
 	{
 		description: "empty",
-		times:       []time.Time{},
+		intervals:   []Interval{},
 		fixture:     "testdata/output_empty.txt",
 	},
 	{
 		description: "august",
-		times: []time.Time{
-			time.Date(2018, time.August, 28, 8, 0, 0, 0, time.Now().Location()),
-			time.Date(2018, time.August, 28, 12, 0, 0, 0, time.Now().Location()),
+		intervals: []Interval{
+			{
+				Start: time.Date(2018, time.August, 28, 8, 0, 0, 0, cest),
+				End:   time.Date(2018, time.August, 28, 12, 0, 0, 0, cest),
+			},
 		},
 		fixture: "testdata/output_august.txt",
 	},
 	{
 		description: "september",
-		times: []time.Time{
-			time.Date(2018, time.September, 1, 10, 0, 0, 0, time.Now().Location()),
-			time.Date(2018, time.September, 1, 11, 42, 0, 0, time.Now().Location()),
-			time.Date(2018, time.September, 1, 14, 0, 0, 0, time.Now().Location()),
-
-			time.Date(2018, time.September, 2, 8, 0, 0, 0, time.Now().Location()),
-			time.Date(2018, time.September, 2, 16, 0, 0, 0, time.Now().Location()),
-
-			time.Date(2018, time.September, 9, 8, 0, 0, 0, time.Now().Location()),
-			time.Date(2018, time.September, 9, 12, 24, 0, 0, time.Now().Location()),
-			time.Date(2018, time.September, 9, 13, 12, 0, 0, time.Now().Location()),
-			time.Date(2018, time.September, 9, 17, 57, 0, 0, time.Now().Location()),
+		intervals: []Interval{
+			{
+				Start: time.Date(2018, time.September, 1, 10, 0, 0, 0, cest),
+				End:   time.Date(2018, time.September, 1, 11, 42, 0, 0, cest),
+			},
+			{
+				Start: time.Date(2018, time.September, 1, 14, 0, 0, 0, cest),
+				Note:  "fixing invoices",
+			},
+
+			{
+				Start: time.Date(2018, time.September, 2, 8, 0, 0, 0, cest),
+				End:   time.Date(2018, time.September, 2, 16, 0, 0, 0, cest),
+			},
+
+			{
+				Start: time.Date(2018, time.September, 9, 8, 0, 0, 0, cest),
+				End:   time.Date(2018, time.September, 9, 12, 24, 0, 0, cest),
+			},
+			{
+				Start: time.Date(2018, time.September, 9, 13, 12, 0, 0, cest),
+				End:   time.Date(2018, time.September, 9, 17, 57, 0, 0, cest),
+			},
 		},
 		fixture: "testdata/output_september.txt",
 	},
@@ -54,7 +67,10 @@ func TestPrint(t *testing.T) {
 		t.Run(tc.description, func(t *testing.T) {
 			output := &bytes.Buffer{}
 
-			print(tc.times, 15*time.Minute, dateFormat, timeFormat, output)
+			sheet := NewSheet(dateFormat, timeFormat, tc.intervals)
+			if err := sheet.Print(PrintOptions{RoundTo: 15 * time.Minute}, output); err != nil {
+				t.Fatalf("Print() error = %v", err)
+			}
 
 			want := string(readFile(t, tc.fixture))
 			if diff := cmp.Diff(strings.Replace(want, "\r\n", "\n", -1), strings.Replace(output.String(), "\r\n", "\n", -1)); diff != "" {
@@ -63,3 +79,165 @@ func TestPrint(t *testing.T) {
 		})
 	}
 }
+
+func TestPrintGroups(t *testing.T) {
+	var timeFormat = "15:04"
+	var dateFormat = "02.01.2006"
+
+	intervals := groupedIntervals()
+
+	output := &bytes.Buffer{}
+
+	sheet := NewSheet(dateFormat, timeFormat, intervals)
+	if err := sheet.Print(PrintOptions{RoundTo: 15 * time.Minute, GroupBy: GroupByProject}, output); err != nil {
+		t.Fatalf("Print() error = %v", err)
+	}
+
+	want := string(readFile(t, "testdata/output_grouped.txt"))
+	if diff := cmp.Diff(strings.Replace(want, "\r\n", "\n", -1), strings.Replace(output.String(), "\r\n", "\n", -1)); diff != "" {
+		t.Errorf("Print() differs: (-want +got)\n%s", diff)
+	}
+}
+
+func TestPrintBalance(t *testing.T) {
+	var timeFormat = "15:04"
+	var dateFormat = "02.01.2006"
+
+	intervals := balanceIntervals()
+
+	sheet := NewSheet(dateFormat, timeFormat, intervals)
+	for _, a := range balanceAbsences() {
+		if err := sheet.AddAbsence(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	output := &bytes.Buffer{}
+
+	// days after the 11th are not counted yet
+	from := time.Date(2018, time.September, 1, 0, 0, 0, 0, time.Now().Location())
+	if err := sheet.PrintRange(from, from.AddDate(0, 1, 0), balanceOptions(), output); err != nil {
+		t.Fatalf("PrintRange() error = %v", err)
+	}
+
+	want := string(readFile(t, "testdata/output_balance.txt"))
+	if diff := cmp.Diff(strings.Replace(want, "\r\n", "\n", -1), strings.Replace(output.String(), "\r\n", "\n", -1)); diff != "" {
+		t.Errorf("PrintRange() differs: (-want +got)\n%s", diff)
+	}
+}
+
+func balanceAbsences() []Absence {
+	return []Absence{
+		{Date: time.Date(2018, time.September, 4, 0, 0, 0, 0, time.Now().Location()), Type: AbsenceSick, Half: true},
+		{Date: time.Date(2018, time.September, 5, 0, 0, 0, 0, time.Now().Location()), Type: AbsenceVacation},
+		{Date: time.Date(2018, time.September, 6, 0, 0, 0, 0, time.Now().Location()), Type: AbsenceHoliday, Note: "Day off"},
+		{Date: time.Date(2018, time.September, 7, 0, 0, 0, 0, time.Now().Location()), Type: AbsenceCompTime},
+		{Date: time.Date(2018, time.September, 20, 0, 0, 0, 0, time.Now().Location()), Type: AbsenceVacation},
+	}
+}
+
+func balanceOptions() PrintOptions {
+	return PrintOptions{
+		RoundTo: 15 * time.Minute,
+		Targets: Targets{time.Monday: 8 * time.Hour, time.Tuesday: 8 * time.Hour, time.Wednesday: 8 * time.Hour, time.Thursday: 8 * time.Hour, time.Friday: 8 * time.Hour},
+		Now:     time.Date(2018, time.September, 11, 12, 0, 0, 0, time.Now().Location()),
+	}
+}
+
+func groupedIntervals() []Interval {
+	return []Interval{
+		{
+			Start:   time.Date(2018, time.September, 1, 8, 0, 0, 0, time.Now().Location()),
+			End:     time.Date(2018, time.September, 1, 12, 0, 0, 0, time.Now().Location()),
+			Project: "acme",
+		},
+		{
+			Start: time.Date(2018, time.September, 1, 13, 0, 0, 0, time.Now().Location()),
+			End:   time.Date(2018, time.September, 1, 14, 0, 0, 0, time.Now().Location()),
+		},
+		{
+			Start:   time.Date(2018, time.September, 2, 9, 0, 0, 0, time.Now().Location()),
+			End:     time.Date(2018, time.September, 2, 11, 30, 0, 0, time.Now().Location()),
+			Project: "acme",
+		},
+	}
+}
+
+func balanceIntervals() []Interval {
+	return []Interval{
+		{
+			Start: time.Date(2018, time.September, 3, 9, 0, 0, 0, time.Now().Location()),
+			End:   time.Date(2018, time.September, 3, 17, 30, 0, 0, time.Now().Location()),
+		},
+		{
+			Start: time.Date(2018, time.September, 4, 8, 30, 0, 0, time.Now().Location()),
+			End:   time.Date(2018, time.September, 4, 13, 30, 0, 0, time.Now().Location()),
+		},
+		{
+			Start: time.Date(2018, time.September, 8, 10, 0, 0, 0, time.Now().Location()),
+			End:   time.Date(2018, time.September, 8, 12, 0, 0, 0, time.Now().Location()),
+		},
+		{
+			Start: time.Date(2018, time.September, 10, 8, 0, 0, 0, time.Now().Location()),
+			End:   time.Date(2018, time.September, 10, 16, 0, 0, 0, time.Now().Location()),
+		},
+	}
+}
+
+func TestFormatters(t *testing.T) {
+	var timeFormat = "15:04"
+	var dateFormat = "02.01.2006"
+
+	tests := []struct {
+		format  string
+		grouped bool
+		fixture string
+	}{
+		{format: "json", fixture: "testdata/output_balance.json"},
+		{format: "csv", fixture: "testdata/output_balance.csv"},
+		{format: "markdown", fixture: "testdata/output_balance.md"},
+		{format: "html", fixture: "testdata/output_balance.html"},
+		{format: "json", grouped: true, fixture: "testdata/output_grouped.json"},
+		{format: "csv", grouped: true, fixture: "testdata/output_grouped.csv"},
+		{format: "markdown", grouped: true, fixture: "testdata/output_grouped.md"},
+		{format: "summary", grouped: true, fixture: "testdata/output_summary.txt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			formatter, err := NewFormatter(tt.format)
+			if err != nil {
+				t.Fatalf("NewFormatter() error = %v", err)
+			}
+
+			sheet := NewSheet(dateFormat, timeFormat, balanceIntervals())
+			for _, a := range balanceAbsences() {
+				if err := sheet.AddAbsence(a); err != nil {
+					t.Fatal(err)
+				}
+			}
+			opts := balanceOptions()
+			if tt.grouped {
+				sheet = NewSheet(dateFormat, timeFormat, groupedIntervals())
+				opts = PrintOptions{RoundTo: 15 * time.Minute, GroupBy: GroupByProject}
+			}
+			opts.Formatter = formatter
+
+			output := &bytes.Buffer{}
+			from := time.Date(2018, time.September, 1, 0, 0, 0, 0, time.Now().Location())
+			if err := sheet.PrintRange(from, from.AddDate(0, 1, 0), opts, output); err != nil {
+				t.Fatalf("PrintRange() error = %v", err)
+			}
+
+			want := string(readFile(t, tt.fixture))
+			if diff := cmp.Diff(strings.Replace(want, "\r\n", "\n", -1), strings.Replace(output.String(), "\r\n", "\n", -1)); diff != "" {
+				t.Errorf("PrintRange() differs: (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNewFormatterUnknown(t *testing.T) {
+	if _, err := NewFormatter("pdf"); err == nil {
+		t.Errorf("NewFormatter() succeeded")
+	}
+}
@@ -0,0 +1,193 @@
+package timesheet
+
+import (
+	"sort"
+	"time"
+)
+
+// Report is the printed part of a timesheet as passed to a Formatter.
+// Intervals are rounded and split at midnight unless NoSplit is set.
+type Report struct {
+	From       time.Time     // Start of the first day.
+	To         time.Time     // End of the last day, exclusive.
+	DateFormat string        // Format of dates in the output.
+	TimeFormat string        // Format of times in the output.
+	RoundTo    time.Duration // Start and end times are rounded to this duration.
+	GroupBy    GroupBy       // How the intervals are grouped.
+	Groups     []Group       // A single group unless grouped by project or tag.
+	Projects   []Project     // Time of every project ordered by name.
+	Total      time.Duration // Time of all intervals, counted once even if in multiple groups.
+	HasBalance bool          // Whether targets are set and the balances are valid.
+	Balance    time.Duration // Overtime of the report, negative for undertime.
+}
+
+// Project is the time tracked for a project, an empty name for intervals
+// without project.
+type Project struct {
+	Name  string
+	Total time.Duration
+}
+
+// Group are the intervals of a project or tag.
+type Group struct {
+	Name    string // Project or tag, empty for intervals without.
+	Weeks   []Week
+	Total   time.Duration
+	Balance time.Duration // Overtime of the group, only valid without grouping.
+}
+
+// Week are the days of a week with intervals or absences.
+type Week struct {
+	Year    int // ISO 8601 year of the week.
+	Number  int // ISO 8601 week number.
+	Days    []Day
+	Total   time.Duration
+	Balance time.Duration // Overtime of the week including days not listed.
+}
+
+// Day are the intervals started and the absences on a day.
+type Day struct {
+	Date      time.Time
+	Intervals []Interval
+	Absences  []Absence // Only listed if the report has a balance.
+	Total     time.Duration
+	Balance   time.Duration
+}
+
+// newReport collects the intervals of the groups by week and day.
+func newReport(groups []group, period *balancePeriod, opts PrintOptions, from, to time.Time, dateFormat, timeFormat string) Report {
+	r := Report{
+		From:       midnight(from),
+		To:         to,
+		DateFormat: dateFormat,
+		TimeFormat: timeFormat,
+		RoundTo:    opts.RoundTo,
+		GroupBy:    opts.GroupBy,
+		HasBalance: period != nil,
+	}
+
+	counted := map[time.Time]bool{}
+	projects := map[string]time.Duration{}
+	for _, g := range groups {
+		rg := Group{Name: g.name}
+
+		days := groupIntervalsByDay(g.intervals)
+		if period != nil {
+			days = addAbsenceDays(days, period)
+		}
+
+		for _, d := range days {
+			year, number := d.date.ISOWeek()
+			if len(rg.Weeks) == 0 || rg.Weeks[len(rg.Weeks)-1].Year != year || rg.Weeks[len(rg.Weeks)-1].Number != number {
+				rg.Weeks = append(rg.Weeks, Week{Year: year, Number: number})
+			}
+			week := &rg.Weeks[len(rg.Weeks)-1]
+
+			day := Day{
+				Date:      midnight(d.date),
+				Intervals: d.intervals,
+				Total:     calculateHours(d.intervals),
+			}
+			if period != nil {
+				day.Absences = period.absences[dayKey(d.date)]
+				day.Balance = day.Total - period.due(day.Date, day.Date.AddDate(0, 0, 1))
+			}
+
+			week.Days = append(week.Days, day)
+			week.Total += day.Total
+			rg.Total += day.Total
+
+			for _, i := range d.intervals {
+				if !counted[i.Start] {
+					counted[i.Start] = true
+					r.Total += i.Duration()
+					projects[i.Project] += i.Duration()
+				}
+			}
+		}
+
+		if period != nil {
+			for n := range rg.Weeks {
+				week := &rg.Weeks[n]
+				monday := week.Days[0].Date.AddDate(0, 0, -(int(week.Days[0].Date.Weekday())+6)%7)
+				week.Balance = week.Total - period.due(monday, monday.AddDate(0, 0, 7))
+			}
+			rg.Balance = rg.Total - period.due(period.from, period.to)
+		}
+
+		r.Groups = append(r.Groups, rg)
+	}
+
+	if period != nil {
+		r.Balance = r.Total - period.due(period.from, period.to)
+	}
+
+	for name, total := range projects {
+		r.Projects = append(r.Projects, Project{Name: name, Total: total})
+	}
+	sort.Slice(r.Projects, func(i, j int) bool { return r.Projects[i].Name < r.Projects[j].Name })
+
+	return r
+}
+
+// LastDay returns the start of the last day of the report.
+func (r Report) LastDay() time.Time {
+	return midnight(r.To.Add(-time.Nanosecond))
+}
+
+// Days returns the days of all weeks of the group.
+func (g Group) Days() []Day {
+	var days []Day
+	for _, w := range g.Weeks {
+		days = append(days, w.Days...)
+	}
+	return days
+}
+
+// day are the intervals started on a date.
+type day struct {
+	date      time.Time
+	intervals []Interval
+}
+
+func groupIntervalsByDay(intervals []Interval) []day {
+	var days []day
+
+	for _, i := range intervals {
+		if len(days) == 0 || !sameDate(days[len(days)-1].date, i.Start) {
+			days = append(days, day{date: i.Start})
+		}
+		days[len(days)-1].intervals = append(days[len(days)-1].intervals, i)
+	}
+
+	return days
+}
+
+// addAbsenceDays adds the days of the period with absences but without
+// intervals.
+func addAbsenceDays(days []day, period *balancePeriod) []day {
+	for key, absences := range period.absences {
+		date := dateIn(absences[0].Date, period.from.Location())
+		if date.Before(period.from) || !date.Before(period.to) {
+			continue
+		}
+		n := sort.Search(len(days), func(n int) bool { return dayKey(days[n].date) >= key })
+		if n < len(days) && dayKey(days[n].date) == key {
+			continue
+		}
+		days = append(days, day{})
+		copy(days[n+1:], days[n:])
+		days[n] = day{date: date}
+	}
+	return days
+}
+
+func calculateHours(intervals []Interval) time.Duration {
+	var hours time.Duration
+
+	for _, i := range intervals {
+		hours += i.Duration()
+	}
+
+	return hours
+}
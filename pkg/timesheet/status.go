@@ -0,0 +1,39 @@
+package timesheet
+
+import "time"
+
+// Status is the state of the timesheet at a point in time.
+type Status struct {
+	Running  bool          // Whether an interval is open.
+	Interval Interval      // The open interval while running.
+	Elapsed  time.Duration // Time since the open interval started.
+	Today    time.Duration // Time tracked on the day including the open interval.
+}
+
+// Status returns the state of the timesheet at the given time.
+func (s *Sheet) Status(now time.Time) Status {
+	var status Status
+
+	if open, ok := s.OpenInterval(); ok && !open.Start.After(now) {
+		status.Running = true
+		status.Interval = open.In(now.Location())
+		status.Elapsed = now.Sub(open.Start)
+	}
+
+	for n, i := range s.intervals {
+		if i.Open() {
+			// only the most recent interval is still running
+			if n != len(s.intervals)-1 || !status.Running {
+				continue
+			}
+			i.End = now
+		}
+		for _, d := range i.In(now.Location()).SplitDays() {
+			if sameDate(d.Start, now) {
+				status.Today += d.Duration()
+			}
+		}
+	}
+
+	return status
+}
@@ -0,0 +1,92 @@
+package timesheet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_Sheet_Status(t *testing.T) {
+	now := time.Date(2018, time.September, 2, 10, 30, 0, 0, time.Now().Location())
+
+	tests := []struct {
+		name      string
+		intervals []Interval
+		want      Status
+	}{
+		{
+			name:      "empty",
+			intervals: []Interval{},
+			want:      Status{},
+		},
+		{
+			name: "stopped",
+			intervals: []Interval{
+				{
+					Start: time.Date(2018, time.September, 1, 8, 0, 0, 0, time.Now().Location()),
+					End:   time.Date(2018, time.September, 1, 12, 0, 0, 0, time.Now().Location()),
+				},
+				{
+					Start: time.Date(2018, time.September, 2, 8, 0, 0, 0, time.Now().Location()),
+					End:   time.Date(2018, time.September, 2, 9, 0, 0, 0, time.Now().Location()),
+				},
+			},
+			want: Status{Today: time.Hour},
+		},
+		{
+			name: "running",
+			intervals: []Interval{
+				{
+					Start: time.Date(2018, time.September, 2, 7, 0, 0, 0, time.Now().Location()),
+					End:   time.Date(2018, time.September, 2, 9, 0, 0, 0, time.Now().Location()),
+				},
+				{
+					Start:   time.Date(2018, time.September, 2, 9, 30, 0, 0, time.Now().Location()),
+					Project: "acme",
+				},
+			},
+			want: Status{
+				Running: true,
+				Interval: Interval{
+					Start:   time.Date(2018, time.September, 2, 9, 30, 0, 0, time.Now().Location()),
+					Project: "acme",
+				},
+				Elapsed: time.Hour,
+				Today:   3 * time.Hour,
+			},
+		},
+		{
+			name: "running since yesterday",
+			intervals: []Interval{
+				{Start: time.Date(2018, time.September, 1, 22, 0, 0, 0, time.Now().Location())},
+			},
+			want: Status{
+				Running:  true,
+				Interval: Interval{Start: time.Date(2018, time.September, 1, 22, 0, 0, 0, time.Now().Location())},
+				Elapsed:  12*time.Hour + 30*time.Minute,
+				Today:    10*time.Hour + 30*time.Minute,
+			},
+		},
+		{
+			name: "forgot to stop yesterday",
+			intervals: []Interval{
+				{Start: time.Date(2018, time.September, 1, 8, 0, 0, 0, time.Now().Location())},
+				{
+					Start: time.Date(2018, time.September, 2, 8, 0, 0, 0, time.Now().Location()),
+					End:   time.Date(2018, time.September, 2, 10, 0, 0, 0, time.Now().Location()),
+				},
+			},
+			want: Status{Today: 2 * time.Hour},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sheet := &Sheet{intervals: tt.intervals}
+			if diff := cmp.Diff(tt.want, sheet.Status(now)); diff != "" {
+				t.Errorf("timeSheet.Status() differs: (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package timesheet
+
+import "os"
+
+// Store persists a timesheet.
+type Store interface {
+	// Load reads the complete timesheet.
+	Load() (*Sheet, error)
+	// Save replaces the stored timesheet.
+	Save(sheet *Sheet) error
+	// Append records the changes of a command at once. Either all events
+	// are recorded or none.
+	Append(events ...Event) error
+}
+
+// EventType describes the kind of change of an event.
+type EventType string
+
+// Supported event types.
+const (
+	EventStart  EventType = "start"  // Start the interval.
+	EventStop   EventType = "stop"   // Stop the open interval at Interval.End.
+	EventAdd    EventType = "add"    // Insert the complete interval.
+	EventEdit   EventType = "edit"   // Replace the interval at Index.
+	EventDelete EventType = "delete" // Remove the interval at Index.
+
+	EventAddAbsence    EventType = "add-absence"    // Record the absence.
+	EventDeleteAbsence EventType = "delete-absence" // Remove the absence at Index.
+)
+
+// Event is a single change of a timesheet.
+type Event struct {
+	Type     EventType
+	Index    int // Position of the changed interval in Sheet.Intervals or absence in Sheet.Absences.
+	Interval Interval
+	Absence  Absence
+}
+
+// FileStore stores the timesheet as JSON file.
+type FileStore struct {
+	Path       string
+	DateFormat string // Format used to write and parse dates.
+	TimeFormat string // Format used to write and parse times.
+	Backups    int    // Number of previous versions kept as "Path.1", "Path.2", ...
+	Upgrade    bool   // Rewrite files of older releases in the current format on load.
+}
+
+// NewFileStore returns a store for the JSON file at path.
+func NewFileStore(path, dateFormat, timeFormat string) *FileStore {
+	return &FileStore{
+		Path:       path,
+		DateFormat: dateFormat,
+		TimeFormat: timeFormat,
+	}
+}
+
+// Load reads the timesheet from the file. A missing file is an empty
+// timesheet.
+func (f *FileStore) Load() (*Sheet, error) {
+	file, err := os.Open(f.Path)
+	if os.IsNotExist(err) {
+		return NewSheet(f.DateFormat, f.TimeFormat, nil), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	sheet, legacy, err := load(file, f.DateFormat, f.TimeFormat)
+	// the file is replaced on upgrade, which fails on Windows while it is
+	// still open
+	file.Close()
+	if err != nil {
+		return nil, err
+	}
+	if legacy && f.Upgrade {
+		if err := f.Save(sheet); err != nil {
+			return nil, err
+		}
+	}
+
+	return sheet, nil
+}
+
+// Save atomically replaces the file with the timesheet.
+func (f *FileStore) Save(sheet *Sheet) error {
+	return writeFile(f.Path, f.Backups, sheet.Save)
+}
+
+// Restore replaces the file with the n-th backup. The replaced file becomes
+// the first backup.
+func (f *FileStore) Restore(n int) error {
+	file, err := os.Open(backupPath(f.Path, n))
+	if err != nil {
+		return err
+	}
+	sheet, err := Load(file, f.DateFormat, f.TimeFormat)
+	file.Close()
+	if err != nil {
+		return err
+	}
+
+	return f.Save(sheet)
+}
+
+// Append applies the events to the timesheet in the file, which is written
+// once.
+func (f *FileStore) Append(events ...Event) error {
+	return appendEvents(f, events)
+}
+
+// MemoryStore keeps the timesheet in memory. It is meant for tests.
+type MemoryStore struct {
+	DateFormat string // Format used to write and parse dates.
+	TimeFormat string // Format used to write and parse times.
+	intervals  []Interval
+	absences   []Absence
+}
+
+// NewMemoryStore returns an empty in-memory store.
+func NewMemoryStore(dateFormat, timeFormat string) *MemoryStore {
+	return &MemoryStore{
+		DateFormat: dateFormat,
+		TimeFormat: timeFormat,
+	}
+}
+
+// Load returns a copy of the stored timesheet.
+func (m *MemoryStore) Load() (*Sheet, error) {
+	sheet := NewSheet(m.DateFormat, m.TimeFormat, m.intervals)
+	sheet.absences = m.absences
+	return sheet, nil
+}
+
+// Save replaces the stored timesheet.
+func (m *MemoryStore) Save(sheet *Sheet) error {
+	m.intervals = sheet.Intervals()
+	m.absences = sheet.Absences()
+	return nil
+}
+
+// Append applies the events to the stored timesheet.
+func (m *MemoryStore) Append(events ...Event) error {
+	return appendEvents(m, events)
+}
+
+// appendEvents implements Append for stores which can only write the
+// complete timesheet.
+func appendEvents(s Store, events []Event) error {
+	sheet, err := s.Load()
+	if err != nil {
+		return err
+	}
+	for _, e := range events {
+		if err := sheet.Apply(e); err != nil {
+			return err
+		}
+	}
+	return s.Save(sheet)
+}
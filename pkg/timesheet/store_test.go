@@ -0,0 +1,247 @@
+package timesheet
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// testStores returns a fresh instance of every store implementation and a
+// function to remove their files.
+func testStores(t *testing.T) (map[string]Store, func()) {
+	dir, err := ioutil.TempDir("", "tt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stores := map[string]Store{
+		"file":    NewFileStore(filepath.Join(dir, "tt.json"), "02.01.2006", "15:04"),
+		"journal": NewJournalStore(filepath.Join(dir, "tt.journal"), "02.01.2006", "15:04"),
+		"memory":  NewMemoryStore("02.01.2006", "15:04"),
+	}
+
+	return stores, func() { os.RemoveAll(dir) }
+}
+
+func TestStore(t *testing.T) {
+	first := Interval{
+		Start:   time.Date(2018, time.September, 1, 8, 0, 0, 0, time.Now().Location()),
+		End:     time.Date(2018, time.September, 1, 12, 0, 0, 0, time.Now().Location()),
+		Project: "acme",
+	}
+	second := Interval{
+		Start: time.Date(2018, time.September, 2, 22, 0, 0, 0, time.Now().Location()),
+		End:   time.Date(2018, time.September, 3, 2, 0, 0, 0, time.Now().Location()),
+		Note:  "night shift",
+	}
+
+	stores, cleanup := testStores(t)
+	defer cleanup()
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			sheet, err := store.Load()
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if len(sheet.Intervals()) != 0 {
+				t.Fatalf("Load() of empty store returned %d intervals", len(sheet.Intervals()))
+			}
+
+			if err := sheet.Apply(Event{Type: EventStart, Interval: Interval{Start: first.Start, Project: first.Project}}); err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+			if err := store.Save(sheet); err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+
+			events := []Event{
+				{Type: EventStop, Interval: Interval{End: first.End}},
+				{Type: EventStart, Interval: Interval{Start: second.Start}},
+				{Type: EventStop, Interval: Interval{End: second.End, Note: second.Note}},
+			}
+			for _, e := range events {
+				if err := store.Append(e); err != nil {
+					t.Fatalf("Append(%v) error = %v", e.Type, err)
+				}
+			}
+			if err := store.Append(Event{Type: EventStop, Interval: Interval{End: second.End}}); err == nil {
+				t.Errorf("Append() of invalid event succeeded")
+			}
+
+			sheet, err = store.Load()
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if diff := cmp.Diff([]Interval{first, second}, sheet.Intervals()); diff != "" {
+				t.Errorf("Load() differs: (-want +got)\n%s", diff)
+			}
+
+			third := Interval{
+				Start: time.Date(2018, time.September, 1, 13, 0, 0, 0, time.Now().Location()),
+				End:   time.Date(2018, time.September, 1, 17, 0, 0, 0, time.Now().Location()),
+				Tags:  []string{"review"},
+			}
+			edited := first
+			edited.Project = "initech"
+			events = []Event{
+				{Type: EventAdd, Interval: third},
+				{Type: EventEdit, Index: 0, Interval: edited},
+				{Type: EventDelete, Index: 2},
+			}
+			if err := store.Append(events...); err != nil {
+				t.Fatalf("Append() error = %v", err)
+			}
+			// nothing is recorded if any event is invalid
+			if err := store.Append(Event{Type: EventDelete, Index: 0}, Event{Type: EventDelete, Index: 5}); err == nil {
+				t.Errorf("Append() of invalid events succeeded")
+			}
+
+			sheet, err = store.Load()
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if diff := cmp.Diff([]Interval{edited, third}, sheet.Intervals()); diff != "" {
+				t.Errorf("Load() after edit differs: (-want +got)\n%s", diff)
+			}
+
+			vacation := Absence{Date: time.Date(2018, time.September, 3, 0, 0, 0, 0, time.UTC), Type: AbsenceVacation, Half: true}
+			holiday := Absence{Date: time.Date(2018, time.September, 4, 0, 0, 0, 0, time.UTC), Type: AbsenceHoliday, Note: "Labour Day"}
+			events = []Event{
+				{Type: EventAddAbsence, Absence: holiday},
+				{Type: EventAddAbsence, Absence: vacation},
+				{Type: EventAddAbsence, Absence: Absence{Date: vacation.Date, Type: AbsenceSick}},
+				{Type: EventDeleteAbsence, Index: 1},
+			}
+			if err := store.Append(events...); err != nil {
+				t.Fatalf("Append() error = %v", err)
+			}
+			if err := store.Append(Event{Type: EventAddAbsence, Absence: holiday}); err == nil {
+				t.Errorf("Append() of duplicate absence succeeded")
+			}
+
+			sheet, err = store.Load()
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if diff := cmp.Diff([]Absence{vacation, holiday}, sheet.Absences()); diff != "" {
+				t.Errorf("Load() absences differ: (-want +got)\n%s", diff)
+			}
+			if diff := cmp.Diff([]Interval{edited, third}, sheet.Intervals()); diff != "" {
+				t.Errorf("Load() after absences differs: (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFileStoreRestore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store := NewFileStore(filepath.Join(dir, "tt.json"), "02.01.2006", "15:04")
+	store.Backups = 3
+
+	start := time.Date(2018, time.September, 1, 8, 0, 0, 0, time.Now().Location())
+	events := []Event{
+		{Type: EventStart, Interval: Interval{Start: start}},
+		{Type: EventStop, Interval: Interval{End: start.Add(time.Hour)}},
+	}
+	for _, e := range events {
+		if err := store.Append(e); err != nil {
+			t.Fatalf("Append(%v) error = %v", e.Type, err)
+		}
+	}
+
+	if err := store.Restore(1); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	sheet, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if diff := cmp.Diff([]Interval{{Start: start}}, sheet.Intervals()); diff != "" {
+		t.Errorf("Restore() differs: (-want +got)\n%s", diff)
+	}
+
+	// the restored version is a backup itself
+	if err := store.Restore(1); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	sheet, err = store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if diff := cmp.Diff([]Interval{{Start: start, End: start.Add(time.Hour)}}, sheet.Intervals()); diff != "" {
+		t.Errorf("Restore() differs: (-want +got)\n%s", diff)
+	}
+
+	if err := store.Restore(4); err == nil {
+		t.Errorf("Restore() of missing backup succeeded")
+	}
+
+	// the events of one Append are written at once, the backup is the
+	// state before all of them
+	var absences []Event
+	for day := 3; day <= 5; day++ {
+		date := time.Date(2018, time.September, day, 0, 0, 0, 0, time.Now().Location())
+		absences = append(absences, Event{Type: EventAddAbsence, Absence: Absence{Date: date, Type: AbsenceVacation}})
+	}
+	if err := store.Append(absences...); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	if err := store.Restore(1); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	sheet, err = store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(sheet.Absences()) != 0 {
+		t.Errorf("Restore() after Append() has %d absences, want 0", len(sheet.Absences()))
+	}
+}
+
+func TestFileStoreUpgrade(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "tt.json")
+	legacy := readFile(t, "testdata/legacy/details.json")
+	if err := ioutil.WriteFile(path, legacy, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	store := NewFileStore(path, "2006-01-02", "15:04")
+	store.Backups = 1
+
+	// only read without upgrade
+	if _, err := store.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if diff := cmp.Diff(string(legacy), string(readFile(t, path))); diff != "" {
+		t.Errorf("Load() without upgrade changed file: (-want +got)\n%s", diff)
+	}
+
+	store.Upgrade = true
+	if _, err := store.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	want := strings.Replace(string(readFile(t, "testdata/details.json")), "\r\n", "\n", -1)
+	if diff := cmp.Diff(want, string(readFile(t, path))); diff != "" {
+		t.Errorf("Load() with upgrade differs: (-want +got)\n%s", diff)
+	}
+	if diff := cmp.Diff(string(legacy), string(readFile(t, backupPath(path, 1)))); diff != "" {
+		t.Errorf("Load() with upgrade didn't keep backup: (-want +got)\n%s", diff)
+	}
+}
@@ -0,0 +1,189 @@
+package timesheet
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// TemplateFuncs are the functions available in report templates in addition
+// to the predefined functions of text/template.
+var TemplateFuncs = map[string]interface{}{
+	// hours formats a duration as decimal hours (ie. "8.50").
+	"hours": func(d time.Duration) string { return fmt.Sprintf("%.2f", d.Hours()) },
+	// signed formats a duration as decimal hours with sign (ie. "+0.50").
+	"signed": func(d time.Duration) string { return fmt.Sprintf("%+.2f", d.Hours()) },
+	// interval formats start and end of an interval (ie. "10:00-12:30").
+	"interval": formatInterval,
+	// absence formats an absence (ie. "half vacation (dentist)").
+	"absence": formatAbsence,
+	// day formats the intervals and absences of a day (ie. "08:30-13:30 half sick").
+	"day": formatDay,
+	// notes formats the notes of a day (ie. "08:30-13:30 fixing invoices; ...").
+	"notes": formatNotes,
+	// label returns the label of a grouping (ie. "Project").
+	"label": func(by GroupBy) string { return groupLabels[by] },
+	// group returns the name of a group or "(none)".
+	"group": groupName,
+	// join joins strings with a separator.
+	"join": func(s []string, sep string) string { return strings.Join(s, sep) },
+	// markdown escapes text for a markdown table cell.
+	"markdown": markdownEscape,
+}
+
+// templateFormatter renders the report with a template.
+type templateFormatter struct {
+	execute func(w io.Writer, data interface{}) error
+}
+
+// NewTemplateFormatter parses a template rendering a Report. HTML templates
+// use html/template, which escapes the output for HTML.
+func NewTemplateFormatter(name, text string, html bool) (Formatter, error) {
+	if html {
+		t, err := htmltemplate.New(name).Funcs(TemplateFuncs).Parse(text)
+		if err != nil {
+			return nil, err
+		}
+		return templateFormatter{execute: t.Execute}, nil
+	}
+
+	t, err := template.New(name).Funcs(TemplateFuncs).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	return templateFormatter{execute: t.Execute}, nil
+}
+
+func (f templateFormatter) Format(w io.Writer, r Report) error {
+	return f.execute(w, r)
+}
+
+// formatDay returns the intervals and absences of the day as written in the
+// output (ie. "08:30-13:30 half sick").
+func formatDay(d Day, timeFormat string) string {
+	var parts []string
+	for _, i := range d.Intervals {
+		parts = append(parts, formatInterval(i, timeFormat))
+	}
+	for _, a := range d.Absences {
+		parts = append(parts, formatAbsence(a))
+	}
+	return strings.Join(parts, " ")
+}
+
+// formatNotes returns the notes of the intervals of the day (ie.
+// "10:00-12:30 fixing invoices; 14:00- support").
+func formatNotes(d Day, timeFormat string) string {
+	var notes []string
+	for _, i := range d.Intervals {
+		if i.Note != "" {
+			notes = append(notes, formatInterval(i, timeFormat)+" "+i.Note)
+		}
+	}
+	return strings.Join(notes, "; ")
+}
+
+// markdownEscape escapes the characters which would break a table cell or
+// start formatting.
+func markdownEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "|", `\|`, "*", `\*`, "_", `\_`, "\n", " ").Replace(s)
+}
+
+// textTemplate lists the intervals by day. With a balance every day and
+// week is followed by the difference to its target.
+const textTemplate = `
+{{- range $n, $g := .Groups}}
+{{- if $.GroupBy}}{{if $n}}
+{{end}}{{label $.GroupBy}}: {{group $g}}
+
+{{end}}
+{{- range $w, $week := .Weeks}}{{if $w}}
+{{end}}
+{{- range .Days}}{{.Date.Format $.DateFormat}}  {{hours .Total}} {{if $.HasBalance}} {{signed .Balance}} {{end}}
+{{- range .Intervals}} {{interval . $.TimeFormat}}{{end}}
+{{- range .Absences}} {{absence .}}{{end}}
+{{range .Intervals}}{{if .Note}}  {{interval . $.TimeFormat}}  {{.Note}}
+{{end}}{{end}}
+{{- end}}
+{{- if $.HasBalance}}{{printf "%-*s" (len ((index .Days 0).Date.Format $.DateFormat)) (printf "Week %d" .Number)}}  {{hours .Total}}  {{signed .Balance}}
+{{end}}
+{{- end}}
+{{- if .Weeks}}
+Total: {{hours .Total}}
+{{if $.HasBalance}}Balance: {{signed .Balance}}
+{{end}}
+{{- end}}
+{{- end}}`
+
+// markdownTemplate writes a table of the days of every group.
+const markdownTemplate = `
+{{- range $n, $g := .Groups}}{{if $n}}
+{{end}}
+{{- if $.GroupBy}}## {{label $.GroupBy}}: {{markdown (group $g)}}
+
+{{end}}
+{{- if $.HasBalance}}| Date | Hours | Balance | Intervals | Notes |
+|------|------:|--------:|-----------|-------|
+{{else}}| Date | Hours | Intervals | Notes |
+|------|------:|-----------|-------|
+{{end}}
+{{- range .Weeks}}
+{{- range .Days}}| {{.Date.Format $.DateFormat}} | {{hours .Total}} |{{if $.HasBalance}} {{signed .Balance}} |{{end}} {{markdown (day . $.TimeFormat)}} | {{markdown (notes . $.TimeFormat)}} |
+{{end}}
+{{- if $.HasBalance}}| **Week {{.Number}}** | {{hours .Total}} | {{signed .Balance}} | | |
+{{end}}
+{{- end}}
+**Total:** {{hours .Total}}
+{{if $.HasBalance}}**Balance:** {{signed .Balance}}
+{{end}}
+{{- end}}`
+
+// htmlTemplate writes a standalone HTML document with a table of the days
+// of every group.
+const htmlTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Timesheet {{.From.Format .DateFormat}}</title>
+</head>
+<body>
+{{- range .Groups}}
+{{- if $.GroupBy}}
+<h2>{{label $.GroupBy}}: {{group .}}</h2>
+{{- end}}
+<table>
+<thead>
+<tr><th>Date</th><th>Hours</th>{{if $.HasBalance}}<th>Balance</th>{{end}}<th>Intervals</th><th>Notes</th></tr>
+</thead>
+<tbody>
+{{- range .Weeks}}
+{{- range .Days}}
+<tr><td>{{.Date.Format $.DateFormat}}</td><td>{{hours .Total}}</td>{{if $.HasBalance}}<td>{{signed .Balance}}</td>{{end}}<td>{{day . $.TimeFormat}}</td><td>
+{{- range .Intervals}}{{if .Note}}<div>{{interval . $.TimeFormat}} {{.Note}}</div>{{end}}{{end -}}
+</td></tr>
+{{- end}}
+{{- if $.HasBalance}}
+<tr><th>Week {{.Number}}</th><td>{{hours .Total}}</td><td>{{signed .Balance}}</td><td></td><td></td></tr>
+{{- end}}
+{{- end}}
+</tbody>
+<tfoot>
+<tr><th>Total</th><td>{{hours .Total}}</td>{{if $.HasBalance}}<td>{{signed .Balance}}</td>{{end}}<td></td><td></td></tr>
+</tfoot>
+</table>
+{{- end}}
+</body>
+</html>
+`
+
+// summaryTemplate writes the hours of every project.
+const summaryTemplate = `{{.From.Format .DateFormat}} - {{.LastDay.Format .DateFormat}}
+
+{{range .Projects}}{{printf "%7s" (hours .Total)}}  {{or .Name "(none)"}}
+{{end}}
+Total: {{hours .Total}}
+{{if .HasBalance}}Balance: {{signed .Balance}}
+{{end}}`
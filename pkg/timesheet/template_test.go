@@ -0,0 +1,78 @@
+package timesheet
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestNewTemplateFormatter(t *testing.T) {
+	intervals := []Interval{
+		{
+			Start:   time.Date(2018, time.September, 3, 9, 0, 0, 0, time.Now().Location()),
+			End:     time.Date(2018, time.September, 3, 12, 7, 0, 0, time.Now().Location()),
+			Project: "acme",
+			Note:    "<b>invoices</b>",
+		},
+		{
+			Start: time.Date(2018, time.September, 4, 13, 0, 0, 0, time.Now().Location()),
+			End:   time.Date(2018, time.September, 4, 14, 0, 0, 0, time.Now().Location()),
+		},
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		html     bool
+		want     string
+	}{
+		{
+			name:     "days",
+			template: `{{range .Groups}}{{range .Days}}{{.Date.Format "2006-01-02"}} {{hours .Total}}{{range .Intervals}} {{interval . $.TimeFormat}} {{.Note}}{{end}};{{end}}{{end}}`,
+			want:     "2018-09-03 3.00 09:00-12:00 <b>invoices</b>;2018-09-04 1.00 13:00-14:00 ;",
+		},
+		{
+			name:     "projects",
+			template: `{{range .Projects}}{{or .Name "-"}}={{hours .Total}} {{end}}{{.RoundTo}} {{hours .Total}}`,
+			want:     "-=1.00 acme=3.00 15m0s 4.00",
+		},
+		{
+			name:     "html",
+			template: `{{range .Groups}}{{range .Days}}<p>{{notes . $.TimeFormat}}</p>{{end}}{{end}}`,
+			html:     true,
+			want:     "<p>09:00-12:00 &lt;b&gt;invoices&lt;/b&gt;</p><p></p>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			formatter, err := NewTemplateFormatter(tt.name, tt.template, tt.html)
+			if err != nil {
+				t.Fatalf("NewTemplateFormatter() error = %v", err)
+			}
+
+			sheet := NewSheet("02.01.2006", "15:04", intervals)
+			var got bytes.Buffer
+			if err := sheet.Print(PrintOptions{RoundTo: 15 * time.Minute, Formatter: formatter}, &got); err != nil {
+				t.Fatalf("Print() error = %v", err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("Print() = %q, want %q", got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestNewTemplateFormatterInvalid(t *testing.T) {
+	if _, err := NewTemplateFormatter("invalid", "{{range .Groups}}", false); err == nil {
+		t.Errorf("NewTemplateFormatter() succeeded")
+	}
+
+	formatter, err := NewTemplateFormatter("unknown field", "{{.Unknown}}", false)
+	if err != nil {
+		t.Fatalf("NewTemplateFormatter() error = %v", err)
+	}
+	sheet := NewSheet("02.01.2006", "15:04", []Interval{{Start: time.Now()}})
+	if err := sheet.Print(PrintOptions{Formatter: formatter}, &bytes.Buffer{}); err == nil {
+		t.Errorf("Print() succeeded")
+	}
+}
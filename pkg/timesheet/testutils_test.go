@@ -2,9 +2,20 @@ package timesheet
 
 import (
 	"io/ioutil"
+	"os"
 	"testing"
+	"time"
 )
 
+// cest is the local time zone of the tests, so the UTC offsets in the
+// fixtures don't depend on the machine.
+var cest = time.FixedZone("CEST", 2*60*60)
+
+func TestMain(m *testing.M) {
+	time.Local = cest
+	os.Exit(m.Run())
+}
+
 func readFile(t *testing.T, path string) []byte {
 	bytes, err := ioutil.ReadFile(path)
 	if err != nil {
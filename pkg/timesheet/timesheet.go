@@ -3,104 +3,354 @@ package timesheet
 import (
 	"fmt"
 	"io"
+	"sort"
 	"time"
 )
 
-// Sheet contains the list of times in the timesheet.
+// Sheet contains the list of intervals and absences in the timesheet.
 type Sheet struct {
-	DateFormat string // Format used to write and parse dates.
-	TimeFormat string // Format used to write and parse times.
-	Times      []time.Time
+	DateFormat string         // Format used to print and parse dates.
+	TimeFormat string         // Format used to print and parse times.
+	Location   *time.Location // Time zone of the output, the local one if nil.
+	intervals  []Interval
+	absences   []Absence
 }
 
-// Load initializes a timesheet from the supplied reader.
+// Load initializes a timesheet from the supplied reader. Data files of
+// older releases are read with the given date and time format.
 func Load(r io.Reader, dateFormat, timeFormat string) (*Sheet, error) {
-	times, err := unmarshal(r, dateFormat, timeFormat)
+	sheet, _, err := load(r, dateFormat, timeFormat)
+	return sheet, err
+}
+
+// load is Load which also reports whether the data has the format of an
+// older release.
+func load(r io.Reader, dateFormat, timeFormat string) (*Sheet, bool, error) {
+	intervals, absences, legacy, err := unmarshal(r, dateFormat, timeFormat)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
+	sheet := NewSheet(dateFormat, timeFormat, intervals)
+	sheet.absences = absences
+	return sheet, legacy, nil
+}
+
+// NewSheet initializes a timesheet with the given intervals.
+func NewSheet(dateFormat, timeFormat string, intervals []Interval) *Sheet {
 	sheet := &Sheet{
 		DateFormat: dateFormat,
 		TimeFormat: timeFormat,
-		Times:      times,
+		intervals:  make([]Interval, len(intervals)),
 	}
+	copy(sheet.intervals, intervals)
+
+	sort.SliceStable(sheet.intervals, func(i, j int) bool {
+		return sheet.intervals[i].Start.Before(sheet.intervals[j].Start)
+	})
 
-	return sheet, nil
+	return sheet
 }
 
-// Save writes the timesheet to the supplied writer.
+// Save writes the timesheet to the supplied writer. The data is always
+// written in the canonical format of the data file, the date and time
+// format of the sheet are only used to read files of older releases.
 func (s *Sheet) Save(w io.Writer) error {
-	return marshal(w, s.Times, s.DateFormat, s.TimeFormat)
+	return marshal(w, s.intervals, s.absences)
 }
 
-// Start adds the given time to the sheet as start time.
-func (s *Sheet) Start(start time.Time) error {
-	var last time.Time
-	c := 0
-	for _, t := range s.Times {
-		if sameDate(start, t) {
-			c++
-			last = t
+// Intervals returns all intervals in the sheet ordered by start time.
+func (s *Sheet) Intervals() []Interval {
+	intervals := make([]Interval, len(s.intervals))
+	copy(intervals, s.intervals)
+	return intervals
+}
+
+// DayIndexes returns the positions in Intervals of the intervals started on
+// the date of t in the location of t.
+func (s *Sheet) DayIndexes(t time.Time) []int {
+	var indexes []int
+	for n, i := range s.intervals {
+		if sameDate(i.Start.In(t.Location()), t) {
+			indexes = append(indexes, n)
 		}
 	}
+	return indexes
+}
 
-	if c%2 != 0 {
-		return fmt.Errorf("already started")
+// OpenInterval returns the most recent interval if it is still running.
+func (s *Sheet) OpenInterval() (Interval, bool) {
+	if len(s.intervals) == 0 {
+		return Interval{}, false
 	}
+	last := s.intervals[len(s.intervals)-1]
+	return last, last.Open()
+}
 
-	if start.Before(last) {
-		return fmt.Errorf("start time %s is earlier as last end time %s", start.Format(s.TimeFormat), last.Format(s.TimeFormat))
+// Start adds a new open interval beginning at the start time of the given
+// interval. Its end time is ignored.
+func (s *Sheet) Start(interval Interval) error {
+	start := interval.Start
+	// the open interval may have been started on an earlier day
+	if n := len(s.intervals); n > 0 {
+		last := s.intervals[n-1]
+		if last.Open() {
+			return fmt.Errorf("already started")
+		}
+		if start.Before(last.End) {
+			return fmt.Errorf("start time %s is earlier as last end time %s", s.formatTime(start), s.formatTime(last.End))
+		}
 	}
 
-	s.Times = append(s.Times, start)
+	interval.End = time.Time{}
+	s.insert(interval)
 
 	return nil
 }
 
-// End adds the given time to the sheet as end time.
-func (s *Sheet) End(end time.Time) error {
-	var last time.Time
-	c := 0
-	for _, t := range s.Times {
-		if sameDate(end, t) {
-			c++
-			last = t
-		}
+// End stops the open interval at the given time. The interval may have
+// been started on an earlier day. A non-empty note is added to the note of
+// the interval.
+func (s *Sheet) End(end time.Time, note string) error {
+	i := len(s.intervals) - 1
+	if i < 0 || !s.intervals[i].Open() {
+		return fmt.Errorf("not started")
 	}
 
-	if c%2 == 0 {
-		return fmt.Errorf("not started")
+	if end.Before(s.intervals[i].Start) {
+		return fmt.Errorf("end time %s is earlier as last start time %s", s.formatTime(end), s.formatTime(s.intervals[i].Start))
 	}
 
-	if end.Before(last) {
-		return fmt.Errorf("end time %s is earlier as last start time %s", end.Format(s.TimeFormat), last.Format(s.TimeFormat))
+	s.intervals[i].End = end
+	s.intervals[i].AddNote(note)
+
+	return nil
+}
+
+// Insert adds the interval at any point in time. Only the most recent
+// interval may be open.
+func (s *Sheet) Insert(interval Interval) error {
+	if err := s.validate(interval, -1); err != nil {
+		return err
 	}
+	s.insert(interval)
+	return nil
+}
 
-	s.Times = append(s.Times, end)
+// Update replaces the n-th interval as returned by Intervals.
+func (s *Sheet) Update(n int, interval Interval) error {
+	if n < 0 || n >= len(s.intervals) {
+		return fmt.Errorf("interval %d does not exist", n)
+	}
+	if err := s.validate(interval, n); err != nil {
+		return err
+	}
+	s.remove(n)
+	s.insert(interval)
+	return nil
+}
 
+// Remove deletes the n-th interval as returned by Intervals.
+func (s *Sheet) Remove(n int) error {
+	if n < 0 || n >= len(s.intervals) {
+		return fmt.Errorf("interval %d does not exist", n)
+	}
+	s.remove(n)
 	return nil
 }
 
+// Apply performs the change described by the event.
+func (s *Sheet) Apply(e Event) error {
+	switch e.Type {
+	case EventStart:
+		return s.Start(e.Interval)
+	case EventStop:
+		return s.End(e.Interval.End, e.Interval.Note)
+	case EventAdd:
+		return s.Insert(e.Interval)
+	case EventEdit:
+		return s.Update(e.Index, e.Interval)
+	case EventDelete:
+		return s.Remove(e.Index)
+	case EventAddAbsence:
+		return s.AddAbsence(e.Absence)
+	case EventDeleteAbsence:
+		return s.RemoveAbsence(e.Index)
+	default:
+		return fmt.Errorf("unknown event '%s'", e.Type)
+	}
+}
+
+// PrintOptions controls which intervals are printed and how.
+type PrintOptions struct {
+	RoundTo   time.Duration // Round start and end times to this duration.
+	Filter    Filter        // Only print intervals matching the filter.
+	GroupBy   GroupBy       // Print a separate list for each project or tag.
+	NoSplit   bool          // Count intervals crossing midnight on their start day.
+	Targets   Targets       // Print the balance against the targets if set.
+	Now       time.Time     // Targets of days after Now are not counted, zero counts all days.
+	Formatter Formatter     // Output format, the text layout if nil.
+}
+
 // Print writes the complete timesheet to the supplied writer.
-func (s *Sheet) Print(roundTo time.Duration, w io.Writer) {
-	print(s.Times, roundTo, s.DateFormat, s.TimeFormat, w)
+func (s *Sheet) Print(opts PrintOptions, w io.Writer) error {
+	if len(s.intervals) == 0 {
+		return nil
+	}
+	// the balance covers all days from the first to the last interval
+	from := s.intervals[0].Start.In(s.location())
+	last := s.intervals[len(s.intervals)-1].Start
+	for _, i := range s.intervals {
+		if i.End.After(last) {
+			last = i.End
+		}
+	}
+	last = last.In(s.location())
+	to := midnight(last).AddDate(0, 0, 1)
+	return s.print(from, to, func(Interval) bool { return true }, opts, w)
 }
 
 // PrintMonth writes the given month to the supplied writer.
-func (s *Sheet) PrintMonth(month time.Month, roundTo time.Duration, w io.Writer) {
-	var times []time.Time
+func (s *Sheet) PrintMonth(year int, month time.Month, opts PrintOptions, w io.Writer) error {
+	from := time.Date(year, month, 1, 0, 0, 0, 0, s.location())
+	return s.PrintRange(from, from.AddDate(0, 1, 0), opts, w)
+}
 
-	for _, t := range s.Times {
-		if t.Month() != month {
+// PrintRange writes the days between from and to to the supplied writer.
+// Intervals crossing from or to are split at midnight unless NoSplit is
+// set.
+func (s *Sheet) PrintRange(from, to time.Time, opts PrintOptions, w io.Writer) error {
+	return s.Range(from, to).print(from, to, func(i Interval) bool {
+		return !i.Start.Before(from) && i.Start.Before(to)
+	}, opts, w)
+}
+
+// Range returns a timesheet with the intervals overlapping the time between
+// from and to and the absences of the days in between.
+func (s *Sheet) Range(from, to time.Time) *Sheet {
+	var intervals []Interval
+	for _, i := range s.intervals {
+		if i.Overlaps(from, to) {
+			intervals = append(intervals, i)
+		}
+	}
+	sheet := NewSheet(s.DateFormat, s.TimeFormat, intervals)
+	sheet.Location = s.Location
+	for _, a := range s.absences {
+		// absences are days, compare them in the time zone of from
+		date := dateIn(a.Date, from.Location())
+		if !date.Before(midnight(from)) && date.Before(to) {
+			sheet.absences = append(sheet.absences, a)
+		}
+	}
+	return sheet
+}
+
+func (s *Sheet) print(from, to time.Time, include func(Interval) bool, opts PrintOptions, w io.Writer) error {
+	var intervals []Interval
+
+	for _, i := range s.intervals {
+		i = i.In(from.Location()).Round(opts.RoundTo)
+
+		days := []Interval{i}
+		if !opts.NoSplit {
+			days = i.SplitDays()
+		}
+
+		for _, d := range days {
+			if !include(d) || !opts.Filter.Match(d) {
+				continue
+			}
+			intervals = append(intervals, d)
+		}
+	}
+
+	formatter := opts.Formatter
+	if formatter == nil {
+		var err error
+		if formatter, err = NewFormatter("text"); err != nil {
+			return err
+		}
+	}
+	period := newBalancePeriod(from, to, s.absences, opts)
+	return formatter.Format(w, newReport(groupIntervals(intervals, opts.GroupBy), period, opts, from, to, s.DateFormat, s.TimeFormat))
+}
+
+// insert adds the interval while keeping the intervals ordered by start.
+func (s *Sheet) insert(interval Interval) {
+	i := sort.Search(len(s.intervals), func(i int) bool {
+		return interval.Start.Before(s.intervals[i].Start)
+	})
+	s.intervals = append(s.intervals, Interval{})
+	copy(s.intervals[i+1:], s.intervals[i:])
+	s.intervals[i] = interval
+}
+
+// remove deletes the n-th interval.
+func (s *Sheet) remove(n int) {
+	s.intervals = append(s.intervals[:n], s.intervals[n+1:]...)
+}
+
+// validate checks that the interval fits into the sheet without the
+// interval at index skip. An open interval has to be the most recent one
+// and covers all time after its start. Older intervals left open only
+// block their start time.
+func (s *Sheet) validate(interval Interval, skip int) error {
+	if !interval.Open() && interval.End.Before(interval.Start) {
+		return fmt.Errorf("end time %s is earlier as start time %s", s.formatTime(interval.End), s.formatTime(interval.Start))
+	}
+
+	last := len(s.intervals) - 1
+	if last == skip {
+		last--
+	}
+
+	to := interval.End
+	if interval.Open() {
+		to = endOfTime
+	}
+
+	for n, other := range s.intervals {
+		if n == skip {
 			continue
 		}
-		times = append(times, t)
+		if interval.Open() && !other.Start.Before(interval.Start) {
+			return fmt.Errorf("open interval %s has to be the most recent", s.format(interval))
+		}
+		span := other
+		if other.Open() && n != last {
+			span.End = other.Start
+		}
+		if other.Start.Equal(interval.Start) || span.Overlaps(interval.Start, to) {
+			return fmt.Errorf("interval %s overlaps %s", s.format(interval), s.format(other))
+		}
 	}
 
-	print(times, roundTo, s.DateFormat, s.TimeFormat, w)
+	return nil
+}
+
+// format returns the interval as written in the output.
+func (s *Sheet) format(i Interval) string {
+	i = i.In(s.location())
+	return i.Start.Format(s.DateFormat) + " " + formatInterval(i, s.TimeFormat)
+}
+
+// formatTime returns the time as written in the output.
+func (s *Sheet) formatTime(t time.Time) string {
+	return t.In(s.location()).Format(s.TimeFormat)
 }
 
+// location returns the time zone of the output.
+func (s *Sheet) location() *time.Location {
+	if s.Location == nil {
+		return time.Local
+	}
+	return s.Location
+}
+
+// endOfTime is the end of open intervals when checking for overlaps.
+var endOfTime = time.Date(9999, time.December, 31, 23, 59, 59, 0, time.UTC)
+
 func sameDate(a, b time.Time) bool {
 	aYear, aMonth, aDay := a.Date()
 	bYear, bMonth, bDay := b.Date()
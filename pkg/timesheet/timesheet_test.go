@@ -1,6 +1,8 @@
 package timesheet
 
 import (
+	"bytes"
+	"strings"
 	"testing"
 	"time"
 
@@ -11,94 +13,141 @@ func Test_Sheet_Start(t *testing.T) {
 	tests := []struct {
 		name    string
 		start   time.Time
-		before  []time.Time
-		after   []time.Time
+		before  []Interval
+		after   []Interval
 		wantErr bool
 	}{
 		{
 			name:   "first entry",
 			start:  time.Date(2018, time.September, 1, 10, 0, 0, 0, time.Now().Location()),
-			before: []time.Time{},
-			after: []time.Time{
-				time.Date(2018, time.September, 1, 10, 0, 0, 0, time.Now().Location()),
+			before: []Interval{},
+			after: []Interval{
+				{Start: time.Date(2018, time.September, 1, 10, 0, 0, 0, time.Now().Location())},
 			},
 			wantErr: false,
 		},
 		{
 			name:  "second entry",
 			start: time.Date(2018, time.September, 1, 13, 0, 0, 0, time.Now().Location()),
-			before: []time.Time{
-				time.Date(2018, time.September, 1, 8, 0, 0, 0, time.Now().Location()),
-				time.Date(2018, time.September, 1, 12, 0, 0, 0, time.Now().Location()),
+			before: []Interval{
+				{
+					Start: time.Date(2018, time.September, 1, 8, 0, 0, 0, time.Now().Location()),
+					End:   time.Date(2018, time.September, 1, 12, 0, 0, 0, time.Now().Location()),
+				},
 			},
-			after: []time.Time{
-				time.Date(2018, time.September, 1, 8, 0, 0, 0, time.Now().Location()),
-				time.Date(2018, time.September, 1, 12, 0, 0, 0, time.Now().Location()),
-				time.Date(2018, time.September, 1, 13, 0, 0, 0, time.Now().Location()),
+			after: []Interval{
+				{
+					Start: time.Date(2018, time.September, 1, 8, 0, 0, 0, time.Now().Location()),
+					End:   time.Date(2018, time.September, 1, 12, 0, 0, 0, time.Now().Location()),
+				},
+				{Start: time.Date(2018, time.September, 1, 13, 0, 0, 0, time.Now().Location())},
 			},
 			wantErr: false,
 		},
 		{
 			name:  "new day",
 			start: time.Date(2018, time.September, 2, 8, 0, 0, 0, time.Now().Location()),
-			before: []time.Time{
-				time.Date(2018, time.September, 1, 8, 0, 0, 0, time.Now().Location()),
-				time.Date(2018, time.September, 1, 12, 0, 0, 0, time.Now().Location()),
+			before: []Interval{
+				{
+					Start: time.Date(2018, time.September, 1, 8, 0, 0, 0, time.Now().Location()),
+					End:   time.Date(2018, time.September, 1, 12, 0, 0, 0, time.Now().Location()),
+				},
 			},
-			after: []time.Time{
-				time.Date(2018, time.September, 1, 8, 0, 0, 0, time.Now().Location()),
-				time.Date(2018, time.September, 1, 12, 0, 0, 0, time.Now().Location()),
-				time.Date(2018, time.September, 2, 8, 0, 0, 0, time.Now().Location()),
+			after: []Interval{
+				{
+					Start: time.Date(2018, time.September, 1, 8, 0, 0, 0, time.Now().Location()),
+					End:   time.Date(2018, time.September, 1, 12, 0, 0, 0, time.Now().Location()),
+				},
+				{Start: time.Date(2018, time.September, 2, 8, 0, 0, 0, time.Now().Location())},
 			},
 			wantErr: false,
 		},
 		{
 			name:  "new day previous not stopped",
 			start: time.Date(2018, time.September, 2, 8, 0, 0, 0, time.Now().Location()),
-			before: []time.Time{
-				time.Date(2018, time.September, 1, 8, 0, 0, 0, time.Now().Location()),
+			before: []Interval{
+				{Start: time.Date(2018, time.September, 1, 8, 0, 0, 0, time.Now().Location())},
 			},
-			after: []time.Time{
-				time.Date(2018, time.September, 1, 8, 0, 0, 0, time.Now().Location()),
-				time.Date(2018, time.September, 2, 8, 0, 0, 0, time.Now().Location()),
+			after: []Interval{
+				{Start: time.Date(2018, time.September, 1, 8, 0, 0, 0, time.Now().Location())},
 			},
-			wantErr: false,
+			wantErr: true,
+		},
+		{
+			name:  "already started before midnight",
+			start: time.Date(2018, time.September, 2, 9, 0, 0, 0, time.Now().Location()),
+			before: []Interval{
+				{
+					Start: time.Date(2018, time.September, 1, 8, 0, 0, 0, time.Now().Location()),
+					End:   time.Date(2018, time.September, 1, 12, 0, 0, 0, time.Now().Location()),
+				},
+				{Start: time.Date(2018, time.September, 1, 22, 0, 0, 0, time.Now().Location())},
+			},
+			after: []Interval{
+				{
+					Start: time.Date(2018, time.September, 1, 8, 0, 0, 0, time.Now().Location()),
+					End:   time.Date(2018, time.September, 1, 12, 0, 0, 0, time.Now().Location()),
+				},
+				{Start: time.Date(2018, time.September, 1, 22, 0, 0, 0, time.Now().Location())},
+			},
+			wantErr: true,
 		},
 		{
 			name:  "already started",
 			start: time.Date(2018, time.September, 1, 16, 0, 0, 0, time.Now().Location()),
-			before: []time.Time{
-				time.Date(2018, time.September, 1, 8, 0, 0, 0, time.Now().Location()),
+			before: []Interval{
+				{Start: time.Date(2018, time.September, 1, 8, 0, 0, 0, time.Now().Location())},
 			},
-			after: []time.Time{
-				time.Date(2018, time.September, 1, 8, 0, 0, 0, time.Now().Location()),
+			after: []Interval{
+				{Start: time.Date(2018, time.September, 1, 8, 0, 0, 0, time.Now().Location())},
+			},
+			wantErr: true,
+		},
+		{
+			name:  "start earlier as end after midnight",
+			start: time.Date(2018, time.September, 2, 1, 0, 0, 0, time.Now().Location()),
+			before: []Interval{
+				{
+					Start: time.Date(2018, time.September, 1, 22, 0, 0, 0, time.Now().Location()),
+					End:   time.Date(2018, time.September, 2, 2, 0, 0, 0, time.Now().Location()),
+				},
+			},
+			after: []Interval{
+				{
+					Start: time.Date(2018, time.September, 1, 22, 0, 0, 0, time.Now().Location()),
+					End:   time.Date(2018, time.September, 2, 2, 0, 0, 0, time.Now().Location()),
+				},
 			},
 			wantErr: true,
 		},
 		{
 			name:  "start earlier as end",
 			start: time.Date(2018, time.September, 1, 8, 0, 0, 0, time.Now().Location()),
-			before: []time.Time{
-				time.Date(2018, time.September, 1, 9, 0, 0, 0, time.Now().Location()),
-				time.Date(2018, time.September, 1, 16, 0, 0, 0, time.Now().Location()),
+			before: []Interval{
+				{
+					Start: time.Date(2018, time.September, 1, 9, 0, 0, 0, time.Now().Location()),
+					End:   time.Date(2018, time.September, 1, 16, 0, 0, 0, time.Now().Location()),
+				},
 			},
-			after: []time.Time{
-				time.Date(2018, time.September, 1, 9, 0, 0, 0, time.Now().Location()),
-				time.Date(2018, time.September, 1, 16, 0, 0, 0, time.Now().Location()),
+			after: []Interval{
+				{
+					Start: time.Date(2018, time.September, 1, 9, 0, 0, 0, time.Now().Location()),
+					End:   time.Date(2018, time.September, 1, 16, 0, 0, 0, time.Now().Location()),
+				},
 			},
 			wantErr: true,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			sheet := &Sheet{Times: tt.before}
-			err := sheet.Start(tt.start)
+			sheet := &Sheet{intervals: tt.before}
+			err := sheet.Start(Interval{Start: tt.start})
 			if (err != nil) != tt.wantErr {
 				t.Errorf("timeSheet.Start() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			if diff := cmp.Diff(tt.after, sheet.Times); diff != "" {
-				t.Errorf("timeSheet.Start() times differ: (-want +got)\n%s", diff)
+			if diff := cmp.Diff(tt.after, sheet.Intervals()); diff != "" {
+				t.Errorf("timeSheet.Start() intervals differ: (-want +got)\n%s", diff)
 			}
 		})
 	}
@@ -108,86 +157,456 @@ func Test_timeSheet_End(t *testing.T) {
 	tests := []struct {
 		name    string
 		end     time.Time
-		before  []time.Time
-		after   []time.Time
+		note    string
+		before  []Interval
+		after   []Interval
 		wantErr bool
 	}{
 		{
 			name: "end first entry",
 			end:  time.Date(2018, time.September, 1, 12, 0, 0, 0, time.Now().Location()),
-			before: []time.Time{
-				time.Date(2018, time.September, 1, 8, 0, 0, 0, time.Now().Location()),
+			before: []Interval{
+				{Start: time.Date(2018, time.September, 1, 8, 0, 0, 0, time.Now().Location())},
 			},
-			after: []time.Time{
-				time.Date(2018, time.September, 1, 8, 0, 0, 0, time.Now().Location()),
-				time.Date(2018, time.September, 1, 12, 0, 0, 0, time.Now().Location()),
+			after: []Interval{
+				{
+					Start: time.Date(2018, time.September, 1, 8, 0, 0, 0, time.Now().Location()),
+					End:   time.Date(2018, time.September, 1, 12, 0, 0, 0, time.Now().Location()),
+				},
 			},
 			wantErr: false,
 		},
 		{
 			name: "end second day",
 			end:  time.Date(2018, time.September, 2, 16, 0, 0, 0, time.Now().Location()),
-			before: []time.Time{
-				time.Date(2018, time.September, 1, 8, 0, 0, 0, time.Now().Location()),
-				time.Date(2018, time.September, 1, 12, 0, 0, 0, time.Now().Location()),
-				time.Date(2018, time.September, 2, 10, 0, 0, 0, time.Now().Location()),
+			before: []Interval{
+				{
+					Start: time.Date(2018, time.September, 1, 8, 0, 0, 0, time.Now().Location()),
+					End:   time.Date(2018, time.September, 1, 12, 0, 0, 0, time.Now().Location()),
+				},
+				{Start: time.Date(2018, time.September, 2, 10, 0, 0, 0, time.Now().Location())},
 			},
-			after: []time.Time{
-				time.Date(2018, time.September, 1, 8, 0, 0, 0, time.Now().Location()),
-				time.Date(2018, time.September, 1, 12, 0, 0, 0, time.Now().Location()),
-				time.Date(2018, time.September, 2, 10, 0, 0, 0, time.Now().Location()),
-				time.Date(2018, time.September, 2, 16, 0, 0, 0, time.Now().Location()),
+			after: []Interval{
+				{
+					Start: time.Date(2018, time.September, 1, 8, 0, 0, 0, time.Now().Location()),
+					End:   time.Date(2018, time.September, 1, 12, 0, 0, 0, time.Now().Location()),
+				},
+				{
+					Start: time.Date(2018, time.September, 2, 10, 0, 0, 0, time.Now().Location()),
+					End:   time.Date(2018, time.September, 2, 16, 0, 0, 0, time.Now().Location()),
+				},
 			},
 			wantErr: false,
 		},
+		{
+			name: "end with note",
+			end:  time.Date(2018, time.September, 1, 12, 0, 0, 0, time.Now().Location()),
+			note: "fixing invoices",
+			before: []Interval{
+				{
+					Start: time.Date(2018, time.September, 1, 8, 0, 0, 0, time.Now().Location()),
+					Note:  "invoices",
+				},
+			},
+			after: []Interval{
+				{
+					Start: time.Date(2018, time.September, 1, 8, 0, 0, 0, time.Now().Location()),
+					End:   time.Date(2018, time.September, 1, 12, 0, 0, 0, time.Now().Location()),
+					Note:  "invoices; fixing invoices",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "end after midnight",
+			end:  time.Date(2018, time.September, 1, 2, 0, 0, 0, time.Now().Location()),
+			before: []Interval{
+				{Start: time.Date(2018, time.August, 31, 22, 0, 0, 0, time.Now().Location())},
+			},
+			after: []Interval{
+				{
+					Start: time.Date(2018, time.August, 31, 22, 0, 0, 0, time.Now().Location()),
+					End:   time.Date(2018, time.September, 1, 2, 0, 0, 0, time.Now().Location()),
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "previous stopped",
+			end:  time.Date(2018, time.September, 2, 16, 0, 0, 0, time.Now().Location()),
+			before: []Interval{
+				{Start: time.Date(2018, time.September, 1, 8, 0, 0, 0, time.Now().Location())},
+				{
+					Start: time.Date(2018, time.September, 2, 8, 0, 0, 0, time.Now().Location()),
+					End:   time.Date(2018, time.September, 2, 12, 0, 0, 0, time.Now().Location()),
+				},
+			},
+			after: []Interval{
+				{Start: time.Date(2018, time.September, 1, 8, 0, 0, 0, time.Now().Location())},
+				{
+					Start: time.Date(2018, time.September, 2, 8, 0, 0, 0, time.Now().Location()),
+					End:   time.Date(2018, time.September, 2, 12, 0, 0, 0, time.Now().Location()),
+				},
+			},
+			wantErr: true,
+		},
 		{
 			name:    "not started",
 			end:     time.Date(2018, time.September, 1, 16, 0, 0, 0, time.Now().Location()),
-			before:  []time.Time{},
-			after:   []time.Time{},
+			before:  []Interval{},
+			after:   []Interval{},
 			wantErr: true,
 		},
 		{
 			name: "new day previous not stopped",
 			end:  time.Date(2018, time.September, 2, 16, 0, 0, 0, time.Now().Location()),
-			before: []time.Time{
-				time.Date(2018, time.September, 1, 8, 0, 0, 0, time.Now().Location()),
-				time.Date(2018, time.September, 2, 10, 0, 0, 0, time.Now().Location()),
+			before: []Interval{
+				{Start: time.Date(2018, time.September, 1, 8, 0, 0, 0, time.Now().Location())},
+				{Start: time.Date(2018, time.September, 2, 10, 0, 0, 0, time.Now().Location())},
 			},
-			after: []time.Time{
-				time.Date(2018, time.September, 1, 8, 0, 0, 0, time.Now().Location()),
-				time.Date(2018, time.September, 2, 10, 0, 0, 0, time.Now().Location()),
-				time.Date(2018, time.September, 2, 16, 0, 0, 0, time.Now().Location()),
+			after: []Interval{
+				{Start: time.Date(2018, time.September, 1, 8, 0, 0, 0, time.Now().Location())},
+				{
+					Start: time.Date(2018, time.September, 2, 10, 0, 0, 0, time.Now().Location()),
+					End:   time.Date(2018, time.September, 2, 16, 0, 0, 0, time.Now().Location()),
+				},
 			},
 			wantErr: false,
 		},
 		{
 			name: "end earlier as start",
 			end:  time.Date(2018, time.September, 1, 8, 0, 0, 0, time.Now().Location()),
-			before: []time.Time{
-				time.Date(2018, time.September, 1, 9, 0, 0, 0, time.Now().Location()),
+			before: []Interval{
+				{Start: time.Date(2018, time.September, 1, 9, 0, 0, 0, time.Now().Location())},
 			},
-			after: []time.Time{
-				time.Date(2018, time.September, 1, 9, 0, 0, 0, time.Now().Location()),
+			after: []Interval{
+				{Start: time.Date(2018, time.September, 1, 9, 0, 0, 0, time.Now().Location())},
 			},
 			wantErr: true,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			sheet := &Sheet{Times: tt.before}
-			err := sheet.End(tt.end)
+			sheet := &Sheet{intervals: tt.before}
+			err := sheet.End(tt.end, tt.note)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("timeSheet.End() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			if diff := cmp.Diff(tt.after, sheet.Times); diff != "" {
+			if diff := cmp.Diff(tt.after, sheet.Intervals()); diff != "" {
 				t.Errorf("timeSheet.End() differs: (-want +got)\n%s", diff)
 			}
 		})
 	}
 }
 
+func Test_Sheet_Insert(t *testing.T) {
+	loc := time.Now().Location()
+	morning := Interval{
+		Start: time.Date(2018, time.September, 1, 8, 0, 0, 0, loc),
+		End:   time.Date(2018, time.September, 1, 12, 0, 0, 0, loc),
+	}
+	afternoon := Interval{
+		Start: time.Date(2018, time.September, 1, 13, 0, 0, 0, loc),
+		End:   time.Date(2018, time.September, 1, 17, 0, 0, 0, loc),
+	}
+	dangling := Interval{Start: time.Date(2018, time.August, 31, 9, 0, 0, 0, loc)}
+	running := Interval{Start: time.Date(2018, time.September, 2, 9, 0, 0, 0, loc)}
+
+	tests := []struct {
+		name     string
+		interval Interval
+		wantErr  bool
+	}{
+		{
+			name: "between",
+			interval: Interval{
+				Start: time.Date(2018, time.September, 1, 12, 0, 0, 0, loc),
+				End:   time.Date(2018, time.September, 1, 13, 0, 0, 0, loc),
+			},
+		},
+		{
+			name: "overlaps start",
+			interval: Interval{
+				Start: time.Date(2018, time.September, 1, 7, 0, 0, 0, loc),
+				End:   time.Date(2018, time.September, 1, 8, 30, 0, 0, loc),
+			},
+			wantErr: true,
+		},
+		{
+			name: "covers interval",
+			interval: Interval{
+				Start: time.Date(2018, time.September, 1, 11, 0, 0, 0, loc),
+				End:   time.Date(2018, time.September, 1, 18, 0, 0, 0, loc),
+			},
+			wantErr: true,
+		},
+		{
+			name: "same start",
+			interval: Interval{
+				Start: morning.Start,
+				End:   morning.Start,
+			},
+			wantErr: true,
+		},
+		{
+			name: "end earlier as start",
+			interval: Interval{
+				Start: time.Date(2018, time.September, 1, 12, 30, 0, 0, loc),
+				End:   time.Date(2018, time.September, 1, 12, 0, 0, 0, loc),
+			},
+			wantErr: true,
+		},
+		{
+			name: "around dangling open interval",
+			interval: Interval{
+				Start: time.Date(2018, time.August, 31, 10, 0, 0, 0, loc),
+				End:   time.Date(2018, time.August, 31, 12, 0, 0, 0, loc),
+			},
+		},
+		{
+			name: "covers dangling open interval",
+			interval: Interval{
+				Start: time.Date(2018, time.August, 31, 8, 0, 0, 0, loc),
+				End:   time.Date(2018, time.August, 31, 12, 0, 0, 0, loc),
+			},
+			wantErr: true,
+		},
+		{
+			name: "after running interval",
+			interval: Interval{
+				Start: time.Date(2018, time.September, 2, 10, 0, 0, 0, loc),
+				End:   time.Date(2018, time.September, 2, 11, 0, 0, 0, loc),
+			},
+			wantErr: true,
+		},
+		{
+			name:     "open in the past",
+			interval: Interval{Start: time.Date(2018, time.September, 1, 12, 30, 0, 0, loc)},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := []Interval{dangling, morning, afternoon, running}
+			sheet := NewSheet("02.01.2006", "15:04", before)
+			err := sheet.Insert(tt.interval)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Sheet.Insert() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			want := before
+			if !tt.wantErr {
+				want = NewSheet("", "", append([]Interval{tt.interval}, before...)).Intervals()
+			}
+			if diff := cmp.Diff(want, sheet.Intervals()); diff != "" {
+				t.Errorf("Sheet.Insert() intervals differ: (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_Sheet_Update(t *testing.T) {
+	loc := time.Now().Location()
+	morning := Interval{
+		Start: time.Date(2018, time.September, 1, 8, 0, 0, 0, loc),
+		End:   time.Date(2018, time.September, 1, 12, 0, 0, 0, loc),
+	}
+	afternoon := Interval{
+		Start: time.Date(2018, time.September, 1, 13, 0, 0, 0, loc),
+	}
+	sheet := NewSheet("02.01.2006", "15:04", []Interval{morning, afternoon})
+
+	// moving the open interval before the morning keeps the order
+	moved := Interval{
+		Start: time.Date(2018, time.September, 1, 6, 0, 0, 0, loc),
+		End:   time.Date(2018, time.September, 1, 7, 0, 0, 0, loc),
+		Note:  "early",
+	}
+	if err := sheet.Update(1, moved); err != nil {
+		t.Fatalf("Sheet.Update() error = %v", err)
+	}
+	if diff := cmp.Diff([]Interval{moved, morning}, sheet.Intervals()); diff != "" {
+		t.Errorf("Sheet.Update() intervals differ: (-want +got)\n%s", diff)
+	}
+
+	// extending an interval into the next one fails
+	extended := moved
+	extended.End = time.Date(2018, time.September, 1, 9, 0, 0, 0, loc)
+	if err := sheet.Update(0, extended); err == nil {
+		t.Errorf("Sheet.Update() of overlapping interval succeeded")
+	}
+
+	// the interval itself doesn't count as overlap
+	extended.End = morning.Start
+	if err := sheet.Update(0, extended); err != nil {
+		t.Errorf("Sheet.Update() error = %v", err)
+	}
+
+	if err := sheet.Update(2, moved); err == nil {
+		t.Errorf("Sheet.Update() of missing interval succeeded")
+	}
+}
+
+func Test_Sheet_Remove(t *testing.T) {
+	loc := time.Now().Location()
+	intervals := []Interval{
+		{Start: time.Date(2018, time.September, 1, 8, 0, 0, 0, loc), End: time.Date(2018, time.September, 1, 12, 0, 0, 0, loc)},
+		{Start: time.Date(2018, time.September, 1, 13, 0, 0, 0, loc), End: time.Date(2018, time.September, 1, 17, 0, 0, 0, loc)},
+		{Start: time.Date(2018, time.September, 2, 8, 0, 0, 0, loc)},
+	}
+	sheet := NewSheet("02.01.2006", "15:04", intervals)
+
+	if err := sheet.Remove(1); err != nil {
+		t.Fatalf("Sheet.Remove() error = %v", err)
+	}
+	if diff := cmp.Diff([]Interval{intervals[0], intervals[2]}, sheet.Intervals()); diff != "" {
+		t.Errorf("Sheet.Remove() intervals differ: (-want +got)\n%s", diff)
+	}
+	if err := sheet.Remove(2); err == nil {
+		t.Errorf("Sheet.Remove() of missing interval succeeded")
+	}
+}
+
+func Test_Sheet_DayIndexes(t *testing.T) {
+	loc := time.Now().Location()
+	sheet := NewSheet("02.01.2006", "15:04", []Interval{
+		{Start: time.Date(2018, time.September, 1, 8, 0, 0, 0, loc), End: time.Date(2018, time.September, 1, 12, 0, 0, 0, loc)},
+		{Start: time.Date(2018, time.September, 1, 22, 0, 0, 0, loc), End: time.Date(2018, time.September, 2, 2, 0, 0, 0, loc)},
+		{Start: time.Date(2018, time.September, 2, 8, 0, 0, 0, loc)},
+	})
+
+	if diff := cmp.Diff([]int{0, 1}, sheet.DayIndexes(time.Date(2018, time.September, 1, 15, 0, 0, 0, loc))); diff != "" {
+		t.Errorf("Sheet.DayIndexes() differs: (-want +got)\n%s", diff)
+	}
+	if diff := cmp.Diff([]int{2}, sheet.DayIndexes(time.Date(2018, time.September, 2, 0, 0, 0, 0, loc))); diff != "" {
+		t.Errorf("Sheet.DayIndexes() differs: (-want +got)\n%s", diff)
+	}
+	if got := sheet.DayIndexes(time.Date(2018, time.September, 3, 0, 0, 0, 0, loc)); got != nil {
+		t.Errorf("Sheet.DayIndexes() = %v, want none", got)
+	}
+}
+
+func Test_Sheet_OpenInterval(t *testing.T) {
+	tests := []struct {
+		name      string
+		intervals []Interval
+		want      Interval
+		wantOpen  bool
+	}{
+		{
+			name:      "empty",
+			intervals: []Interval{},
+			want:      Interval{},
+			wantOpen:  false,
+		},
+		{
+			name: "stopped",
+			intervals: []Interval{
+				{
+					Start: time.Date(2018, time.September, 1, 8, 0, 0, 0, time.Now().Location()),
+					End:   time.Date(2018, time.September, 1, 12, 0, 0, 0, time.Now().Location()),
+				},
+			},
+			want: Interval{
+				Start: time.Date(2018, time.September, 1, 8, 0, 0, 0, time.Now().Location()),
+				End:   time.Date(2018, time.September, 1, 12, 0, 0, 0, time.Now().Location()),
+			},
+			wantOpen: false,
+		},
+		{
+			name: "running",
+			intervals: []Interval{
+				{
+					Start: time.Date(2018, time.September, 1, 8, 0, 0, 0, time.Now().Location()),
+					End:   time.Date(2018, time.September, 1, 12, 0, 0, 0, time.Now().Location()),
+				},
+				{Start: time.Date(2018, time.September, 1, 13, 0, 0, 0, time.Now().Location())},
+			},
+			want:     Interval{Start: time.Date(2018, time.September, 1, 13, 0, 0, 0, time.Now().Location())},
+			wantOpen: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sheet := &Sheet{intervals: tt.intervals}
+			got, open := sheet.OpenInterval()
+			if open != tt.wantOpen {
+				t.Errorf("timeSheet.OpenInterval() open = %v, want %v", open, tt.wantOpen)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("timeSheet.OpenInterval() differs: (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_Sheet_PrintMonth(t *testing.T) {
+	sheet := &Sheet{
+		DateFormat: "02.01.2006",
+		TimeFormat: "15:04",
+		intervals: []Interval{
+			// same month of another year
+			{
+				Start: time.Date(2017, time.August, 30, 8, 0, 0, 0, time.Now().Location()),
+				End:   time.Date(2017, time.August, 30, 17, 0, 0, 0, time.Now().Location()),
+			},
+			{
+				Start: time.Date(2018, time.August, 30, 9, 0, 0, 0, time.Now().Location()),
+				End:   time.Date(2018, time.August, 30, 17, 0, 0, 0, time.Now().Location()),
+			},
+			{
+				Start: time.Date(2018, time.August, 31, 22, 0, 0, 0, time.Now().Location()),
+				End:   time.Date(2018, time.September, 1, 2, 30, 0, 0, time.Now().Location()),
+			},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		month   time.Month
+		noSplit bool
+		fixture string
+	}{
+		{
+			name:    "split start month",
+			month:   time.August,
+			fixture: "testdata/output_split_august.txt",
+		},
+		{
+			name:    "split end month",
+			month:   time.September,
+			fixture: "testdata/output_split_september.txt",
+		},
+		{
+			name:    "no split",
+			month:   time.August,
+			noSplit: true,
+			fixture: "testdata/output_nosplit_august.txt",
+		},
+		{
+			name:    "no split end month",
+			month:   time.September,
+			noSplit: true,
+			fixture: "testdata/output_empty.txt",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := &bytes.Buffer{}
+
+			sheet.PrintMonth(2018, tt.month, PrintOptions{RoundTo: 15 * time.Minute, NoSplit: tt.noSplit}, output)
+
+			want := string(readFile(t, tt.fixture))
+			if diff := cmp.Diff(strings.Replace(want, "\r\n", "\n", -1), strings.Replace(output.String(), "\r\n", "\n", -1)); diff != "" {
+				t.Errorf("timeSheet.PrintMonth() differs: (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestSameDate(t *testing.T) {
 	testTime := time.Date(2018, time.September, 1, 10, 0, 0, 0, time.Now().Location())
 
@@ -219,3 +638,109 @@ func TestSameDate(t *testing.T) {
 		})
 	}
 }
+
+func Test_Sheet_Print_DST(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("time zone database not available: %s", err)
+	}
+	data := `{
+  "version": 3,
+  "days": {
+    "2018-03-25": ["01:00+01:00-04:00+02:00"],
+    "2018-10-27": ["22:00+02:00-2018-10-28T01:00+02:00"],
+    "2018-10-28": ["01:00+02:00-02:30+02:00", "02:30+02:00-02:30+01:00", "02:30+01:00-04:00+01:00"]
+  }
+}`
+	sheet, err := Load(strings.NewReader(data), "02.01.2006", "15:04")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	sheet.Location = berlin
+
+	var got bytes.Buffer
+	sheet.Print(PrintOptions{RoundTo: 15 * time.Minute}, &got)
+
+	// spring forward skips an hour, falling back repeats one
+	want := `25.03.2018  2.00  01:00-04:00
+
+27.10.2018  2.00  22:00-00:00
+28.10.2018  5.00  00:00-01:00 01:00-02:30 02:30-02:30 02:30-04:00
+
+Total: 9.00
+`
+	if diff := cmp.Diff(want, got.String()); diff != "" {
+		t.Errorf("Print() differs: (-want +got)\n%s", diff)
+	}
+}
+
+func Test_Sheet_Print_Location(t *testing.T) {
+	edt := time.FixedZone("EDT", -4*60*60)
+	sheet := NewSheet("02.01.2006", "15:04", []Interval{
+		// recorded while travelling
+		{
+			Start: time.Date(2018, time.September, 3, 20, 0, 0, 0, edt),
+			End:   time.Date(2018, time.September, 3, 22, 0, 0, 0, edt),
+		},
+	})
+
+	tests := []struct {
+		name     string
+		location *time.Location
+		want     string
+	}{
+		{
+			name: "local",
+			want: "04.09.2018  2.00  02:00-04:00\n\nTotal: 2.00\n",
+		},
+		{
+			name:     "recorded",
+			location: edt,
+			want:     "03.09.2018  2.00  20:00-22:00\n\nTotal: 2.00\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sheet.Location = tt.location
+
+			var got bytes.Buffer
+			if err := sheet.Print(PrintOptions{}, &got); err != nil {
+				t.Fatalf("Print() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got.String()); diff != "" {
+				t.Errorf("Print() differs: (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_Sheet_PrintRange(t *testing.T) {
+	sheet := NewSheet("02.01.2006", "15:04", []Interval{
+		{
+			Start: time.Date(2018, time.August, 31, 22, 0, 0, 0, time.Now().Location()),
+			End:   time.Date(2018, time.September, 1, 2, 0, 0, 0, time.Now().Location()),
+		},
+		{
+			Start: time.Date(2018, time.September, 3, 8, 0, 0, 0, time.Now().Location()),
+			End:   time.Date(2018, time.September, 3, 12, 0, 0, 0, time.Now().Location()),
+		},
+		{
+			Start: time.Date(2019, time.September, 1, 8, 0, 0, 0, time.Now().Location()),
+			End:   time.Date(2019, time.September, 1, 12, 0, 0, 0, time.Now().Location()),
+		},
+	})
+
+	from := time.Date(2018, time.September, 1, 0, 0, 0, 0, time.Now().Location())
+	to := time.Date(2018, time.September, 3, 0, 0, 0, 0, time.Now().Location())
+
+	if got := len(sheet.Range(from, to).Intervals()); got != 1 {
+		t.Errorf("Range() returned %d intervals, want 1", got)
+	}
+
+	var got bytes.Buffer
+	sheet.PrintRange(from, to, PrintOptions{RoundTo: 15 * time.Minute}, &got)
+	want := "01.09.2018  2.00  00:00-02:00\n\nTotal: 2.00\n"
+	if diff := cmp.Diff(want, got.String()); diff != "" {
+		t.Errorf("PrintRange() differs: (-want +got)\n%s", diff)
+	}
+}
@@ -0,0 +1,256 @@
+package timesheet
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// Change records the intervals a command replaced, so it can be undone.
+type Change struct {
+	Command string
+	Time    time.Time
+	Removed []Interval // Intervals removed or changed by the command.
+	Added   []Interval // Intervals added by the command.
+
+	RemovedAbsences []Absence // Absences removed by the command.
+	AddedAbsences   []Absence // Absences added by the command.
+}
+
+// Diff returns the change turning the intervals before into the intervals
+// after.
+func Diff(command string, before, after []Interval) Change {
+	c := Change{Command: command, Time: time.Now()}
+	c.Removed = without(before, after)
+	c.Added = without(after, before)
+	return c
+}
+
+// DiffAbsences adds the absences changed between before and after to the
+// change.
+func (c *Change) DiffAbsences(before, after []Absence) {
+	c.RemovedAbsences = withoutAbsences(before, after)
+	c.AddedAbsences = withoutAbsences(after, before)
+}
+
+// Revert returns the events undoing the change. It fails if an interval
+// added by the change was modified since.
+func (s *Sheet) Revert(c Change) ([]Event, error) {
+	used := map[int]bool{}
+	var indexes []int
+	for _, i := range c.Added {
+		n := indexOf(s.intervals, i, used)
+		if n < 0 {
+			return nil, fmt.Errorf("cannot undo %s, interval %s was changed since", c.Command, s.format(i))
+		}
+		used[n] = true
+		indexes = append(indexes, n)
+	}
+
+	// remove from the back so the remaining indexes stay valid
+	sort.Sort(sort.Reverse(sort.IntSlice(indexes)))
+
+	used = map[int]bool{}
+	var absenceIndexes []int
+	for _, a := range c.AddedAbsences {
+		n := absenceIndexOf(s.absences, a, used)
+		if n < 0 {
+			return nil, fmt.Errorf("cannot undo %s, %s on %s was changed since", c.Command, a.Type, a.Date.Format(s.DateFormat))
+		}
+		used[n] = true
+		absenceIndexes = append(absenceIndexes, n)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(absenceIndexes)))
+
+	var events []Event
+	for _, n := range indexes {
+		events = append(events, Event{Type: EventDelete, Index: n})
+	}
+	for _, n := range absenceIndexes {
+		events = append(events, Event{Type: EventDeleteAbsence, Index: n})
+	}
+	for _, i := range c.Removed {
+		events = append(events, Event{Type: EventAdd, Interval: i})
+	}
+	for _, a := range c.RemovedAbsences {
+		events = append(events, Event{Type: EventAddAbsence, Absence: a})
+	}
+
+	return events, nil
+}
+
+// without returns the intervals of a which are not in b.
+func without(a, b []Interval) []Interval {
+	used := map[int]bool{}
+	var rest []Interval
+	for _, i := range a {
+		n := indexOf(b, i, used)
+		if n < 0 {
+			rest = append(rest, i)
+			continue
+		}
+		used[n] = true
+	}
+	return rest
+}
+
+// indexOf returns the index of the first unused interval equal to i or -1.
+func indexOf(intervals []Interval, i Interval, used map[int]bool) int {
+	for n, other := range intervals {
+		if !used[n] && equal(i, other) {
+			return n
+		}
+	}
+	return -1
+}
+
+// withoutAbsences returns the absences of a which are not in b.
+func withoutAbsences(a, b []Absence) []Absence {
+	used := map[int]bool{}
+	var rest []Absence
+	for _, absence := range a {
+		n := absenceIndexOf(b, absence, used)
+		if n < 0 {
+			rest = append(rest, absence)
+			continue
+		}
+		used[n] = true
+	}
+	return rest
+}
+
+// absenceIndexOf returns the index of the first unused absence equal to a
+// or -1.
+func absenceIndexOf(absences []Absence, a Absence, used map[int]bool) int {
+	for n, other := range absences {
+		if !used[n] && a.Date.Equal(other.Date) && a.Type == other.Type && a.Half == other.Half && a.Note == other.Note {
+			return n
+		}
+	}
+	return -1
+}
+
+func equal(a, b Interval) bool {
+	return a.Start.Equal(b.Start) &&
+		a.End.Equal(b.End) &&
+		a.Project == b.Project &&
+		a.Note == b.Note &&
+		a.UID == b.UID &&
+		(len(a.Tags) == 0 && len(b.Tags) == 0 || reflect.DeepEqual(a.Tags, b.Tags))
+}
+
+// History keeps the most recent changes in a JSON file.
+type History struct {
+	Path string
+	Size int // Number of changes kept.
+}
+
+// NewHistory returns the history stored at path keeping 10 changes.
+func NewHistory(path string) *History {
+	return &History{
+		Path: path,
+		Size: 10,
+	}
+}
+
+type historyChange struct {
+	Command string            `json:"command"`
+	Time    time.Time         `json:"time"`
+	Removed []journalInterval `json:"removed,omitempty"`
+	Added   []journalInterval `json:"added,omitempty"`
+
+	RemovedAbsences []fileAbsence `json:"removed_absences,omitempty"`
+	AddedAbsences   []fileAbsence `json:"added_absences,omitempty"`
+}
+
+// Push adds the change and drops the oldest changes beyond Size.
+func (h *History) Push(c Change) error {
+	changes, err := h.load()
+	if err != nil {
+		return err
+	}
+
+	hc := historyChange{Command: c.Command, Time: c.Time}
+	for _, i := range c.Removed {
+		hc.Removed = append(hc.Removed, newJournalInterval(i))
+	}
+	for _, i := range c.Added {
+		hc.Added = append(hc.Added, newJournalInterval(i))
+	}
+	for _, a := range c.RemovedAbsences {
+		hc.RemovedAbsences = append(hc.RemovedAbsences, newFileAbsence(a))
+	}
+	for _, a := range c.AddedAbsences {
+		hc.AddedAbsences = append(hc.AddedAbsences, newFileAbsence(a))
+	}
+	changes = append(changes, hc)
+	if len(changes) > h.Size {
+		changes = changes[len(changes)-h.Size:]
+	}
+
+	return h.save(changes)
+}
+
+// Last returns the most recent change. It returns false if the history is
+// empty.
+func (h *History) Last() (Change, bool, error) {
+	changes, err := h.load()
+	if err != nil || len(changes) == 0 {
+		return Change{}, false, err
+	}
+
+	hc := changes[len(changes)-1]
+	c := Change{Command: hc.Command, Time: hc.Time}
+	for _, i := range hc.Removed {
+		c.Removed = append(c.Removed, i.interval())
+	}
+	for _, i := range hc.Added {
+		c.Added = append(c.Added, i.interval())
+	}
+	if c.RemovedAbsences, err = parseAbsences(hc.RemovedAbsences); err != nil {
+		return Change{}, false, fmt.Errorf("%s: %s", h.Path, err)
+	}
+	if c.AddedAbsences, err = parseAbsences(hc.AddedAbsences); err != nil {
+		return Change{}, false, fmt.Errorf("%s: %s", h.Path, err)
+	}
+
+	return c, true, nil
+}
+
+// DropLast removes the most recent change.
+func (h *History) DropLast() error {
+	changes, err := h.load()
+	if err != nil || len(changes) == 0 {
+		return err
+	}
+	return h.save(changes[:len(changes)-1])
+}
+
+func (h *History) load() ([]historyChange, error) {
+	file, err := os.Open(h.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var changes []historyChange
+	if err := json.NewDecoder(file).Decode(&changes); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("%s: %s", h.Path, err)
+	}
+	return changes, nil
+}
+
+func (h *History) save(changes []historyChange) error {
+	return writeFile(h.Path, 0, func(w io.Writer) error {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(changes)
+	})
+}
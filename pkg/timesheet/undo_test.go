@@ -0,0 +1,159 @@
+package timesheet
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRevert(t *testing.T) {
+	loc := time.Now().Location()
+	morning := Interval{
+		Start: time.Date(2018, time.September, 1, 8, 0, 0, 0, loc),
+		End:   time.Date(2018, time.September, 1, 12, 0, 0, 0, loc),
+	}
+	afternoon := Interval{
+		Start:   time.Date(2018, time.September, 1, 13, 0, 0, 0, loc),
+		Project: "acme",
+	}
+
+	tests := []struct {
+		name   string
+		events []Event
+	}{
+		{
+			name: "start",
+			events: []Event{
+				{Type: EventStop, Interval: Interval{End: time.Date(2018, time.September, 1, 17, 0, 0, 0, loc)}},
+				{Type: EventStart, Interval: Interval{Start: time.Date(2018, time.September, 2, 8, 0, 0, 0, loc)}},
+			},
+		},
+		{
+			name:   "stop",
+			events: []Event{{Type: EventStop, Interval: Interval{End: time.Date(2018, time.September, 1, 17, 0, 0, 0, loc), Note: "done"}}},
+		},
+		{
+			name:   "delete",
+			events: []Event{{Type: EventDelete, Index: 0}},
+		},
+		{
+			name: "edit day",
+			events: []Event{
+				{Type: EventDelete, Index: 1},
+				{Type: EventDelete, Index: 0},
+				{Type: EventAdd, Interval: Interval{Start: morning.Start, End: afternoon.Start}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sheet := NewSheet("02.01.2006", "15:04", []Interval{morning, afternoon})
+			before := sheet.Intervals()
+			for _, e := range tt.events {
+				if err := sheet.Apply(e); err != nil {
+					t.Fatalf("Apply() error = %v", err)
+				}
+			}
+
+			events, err := sheet.Revert(Diff(tt.name, before, sheet.Intervals()))
+			if err != nil {
+				t.Fatalf("Revert() error = %v", err)
+			}
+			for _, e := range events {
+				if err := sheet.Apply(e); err != nil {
+					t.Fatalf("Apply() of reverting event error = %v", err)
+				}
+			}
+			if diff := cmp.Diff(before, sheet.Intervals()); diff != "" {
+				t.Errorf("Revert() intervals differ: (-want +got)\n%s", diff)
+			}
+		})
+	}
+
+	// absences
+	vacation := Absence{Date: time.Date(2018, time.September, 3, 0, 0, 0, 0, loc), Type: AbsenceVacation}
+	sick := Absence{Date: time.Date(2018, time.September, 4, 0, 0, 0, 0, loc), Type: AbsenceSick}
+	absenceSheet := NewSheet("02.01.2006", "15:04", nil)
+	if err := absenceSheet.AddAbsence(vacation); err != nil {
+		t.Fatal(err)
+	}
+	beforeAbsences := absenceSheet.Absences()
+	for _, e := range []Event{{Type: EventDeleteAbsence, Index: 0}, {Type: EventAddAbsence, Absence: sick}} {
+		if err := absenceSheet.Apply(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	absenceChange := Diff("absence", nil, nil)
+	absenceChange.DiffAbsences(beforeAbsences, absenceSheet.Absences())
+	events, err := absenceSheet.Revert(absenceChange)
+	if err != nil {
+		t.Fatalf("Revert() of absences error = %v", err)
+	}
+	for _, e := range events {
+		if err := absenceSheet.Apply(e); err != nil {
+			t.Fatalf("Apply() of reverting event error = %v", err)
+		}
+	}
+	if diff := cmp.Diff(beforeAbsences, absenceSheet.Absences()); diff != "" {
+		t.Errorf("Revert() absences differ: (-want +got)\n%s", diff)
+	}
+
+	// changed after the change was recorded
+	sheet := NewSheet("02.01.2006", "15:04", []Interval{morning})
+	change := Diff("add", nil, []Interval{morning})
+	if err := sheet.Update(0, afternoon); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sheet.Revert(change); err == nil {
+		t.Errorf("Revert() of modified interval succeeded")
+	}
+}
+
+func TestHistory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	h := NewHistory(filepath.Join(dir, "tt.json.undo"))
+	h.Size = 2
+
+	if _, ok, err := h.Last(); ok || err != nil {
+		t.Fatalf("Last() of empty history = %v, %v", ok, err)
+	}
+
+	interval := Interval{
+		Start: time.Date(2018, time.September, 1, 8, 0, 0, 0, time.Now().Location()),
+		Tags:  []string{"review"},
+	}
+	for _, command := range []string{"start", "stop", "add"} {
+		if err := h.Push(Change{Command: command, Added: []Interval{interval}}); err != nil {
+			t.Fatalf("Push() error = %v", err)
+		}
+	}
+
+	for _, want := range []string{"add", "stop"} {
+		c, ok, err := h.Last()
+		if !ok || err != nil {
+			t.Fatalf("Last() = %v, %v", ok, err)
+		}
+		if c.Command != want {
+			t.Errorf("Last() command = %s, want %s", c.Command, want)
+		}
+		if diff := cmp.Diff([]Interval{interval}, c.Added); diff != "" {
+			t.Errorf("Last() intervals differ: (-want +got)\n%s", diff)
+		}
+		if err := h.DropLast(); err != nil {
+			t.Fatalf("DropLast() error = %v", err)
+		}
+	}
+
+	if _, ok, err := h.Last(); ok || err != nil {
+		t.Errorf("Last() after dropping all changes = %v, %v", ok, err)
+	}
+}
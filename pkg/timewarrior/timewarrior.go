@@ -0,0 +1,222 @@
+// Package timewarrior reads and writes the data files of Timewarrior.
+//
+// Timewarrior keeps its intervals in monthly files like "2026-01.data" with
+// one line per interval:
+//
+//	inc 20260105T080000Z - 20260105T120000Z # tag1 "tag two" # "annotation"
+//
+// The end is missing while an interval is open, tags and annotation are
+// optional.
+package timewarrior
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Interval is an "inc" line of a data file.
+type Interval struct {
+	Start      time.Time
+	End        time.Time // Zero if the interval is open.
+	Tags       []string
+	Annotation string
+}
+
+// Open reports whether the interval has no end.
+func (i Interval) Open() bool {
+	return i.End.IsZero()
+}
+
+// timeFormat is the format of the times in data files, always UTC.
+const timeFormat = "20060102T150405Z"
+
+// FileName returns the name of the monthly data file of an interval
+// starting at t (ie. "2026-01.data").
+func FileName(t time.Time) string {
+	return t.UTC().Format("2006-01") + ".data"
+}
+
+// Parse reads the intervals of a data file. Times are in the local time
+// zone.
+func Parse(r io.Reader) ([]Interval, error) {
+	var intervals []Interval
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		i, err := parseLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", n, err)
+		}
+		intervals = append(intervals, i)
+	}
+	return intervals, scanner.Err()
+}
+
+// parseLine parses an "inc" line.
+func parseLine(line string) (Interval, error) {
+	var i Interval
+
+	rest := line
+	if n := strings.Index(line, "#"); n >= 0 {
+		rest = line[:n]
+		var err error
+		if i.Tags, i.Annotation, err = parseTags(line[n+1:]); err != nil {
+			return i, err
+		}
+	}
+
+	fields := strings.Fields(rest)
+	if len(fields) == 0 || fields[0] != "inc" {
+		return i, fmt.Errorf("unknown line '%s'", line)
+	}
+
+	var err error
+	switch len(fields) {
+	case 2:
+		i.Start, err = parseTime(fields[1])
+	case 4:
+		if fields[2] != "-" {
+			return i, fmt.Errorf("invalid interval '%s'", line)
+		}
+		if i.Start, err = parseTime(fields[1]); err != nil {
+			break
+		}
+		if i.End, err = parseTime(fields[3]); err != nil {
+			break
+		}
+		if i.End.Before(i.Start) {
+			return i, fmt.Errorf("end time %s is earlier as start time %s", fields[3], fields[1])
+		}
+	default:
+		return i, fmt.Errorf("invalid interval '%s'", line)
+	}
+
+	return i, err
+}
+
+// parseTags parses the tags after the first "#" and the annotation after
+// the second one. Quoted tags may contain spaces and "#".
+func parseTags(s string) ([]string, string, error) {
+	var tags []string
+	for {
+		s = strings.TrimLeft(s, " \t")
+		switch {
+		case s == "":
+			return tags, "", nil
+		case s[0] == '#':
+			annotation := strings.TrimSpace(s[1:])
+			if strings.HasPrefix(annotation, `"`) {
+				value, rest, err := unquote(annotation)
+				if err != nil {
+					return nil, "", err
+				}
+				if strings.TrimSpace(rest) != "" {
+					return nil, "", fmt.Errorf("unexpected '%s' after annotation", strings.TrimSpace(rest))
+				}
+				annotation = value
+			}
+			return tags, annotation, nil
+		case s[0] == '"':
+			tag, rest, err := unquote(s)
+			if err != nil {
+				return nil, "", err
+			}
+			tags, s = append(tags, tag), rest
+		default:
+			n := strings.IndexAny(s, " \t")
+			if n < 0 {
+				n = len(s)
+			}
+			tags, s = append(tags, s[:n]), s[n:]
+		}
+	}
+}
+
+// unquote returns the value of the quoted string at the start of s and the
+// rest of s. Backslashes escape the next character.
+func unquote(s string) (string, string, error) {
+	var b strings.Builder
+	for n := 1; n < len(s); n++ {
+		switch s[n] {
+		case '\\':
+			if n+1 < len(s) {
+				n++
+			}
+			b.WriteByte(s[n])
+		case '"':
+			return b.String(), s[n+1:], nil
+		default:
+			b.WriteByte(s[n])
+		}
+	}
+	return "", "", fmt.Errorf("unterminated quote in '%s'", s)
+}
+
+// parseTime parses a UTC time of a data file.
+func parseTime(value string) (time.Time, error) {
+	t, err := time.Parse(timeFormat, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time '%s'", value)
+	}
+	return t.In(time.Now().Location()), nil
+}
+
+// Write writes a line for every interval, sorted by start like Timewarrior
+// does.
+func Write(w io.Writer, intervals []Interval) error {
+	sorted := make([]Interval, len(intervals))
+	copy(sorted, intervals)
+	sort.SliceStable(sorted, func(a, b int) bool { return sorted[a].Start.Before(sorted[b].Start) })
+
+	var b strings.Builder
+	for _, i := range sorted {
+		b.WriteString(formatLine(i))
+		b.WriteString("\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// formatLine returns the "inc" line of an interval.
+func formatLine(i Interval) string {
+	line := "inc " + i.Start.UTC().Format(timeFormat)
+	if !i.Open() {
+		line += " - " + i.End.UTC().Format(timeFormat)
+	}
+	if len(i.Tags) == 0 && i.Annotation == "" {
+		return line
+	}
+
+	line += " #"
+	for _, tag := range i.Tags {
+		line += " " + quoteTag(tag)
+	}
+	if i.Annotation != "" {
+		line += " # " + quote(i.Annotation)
+	}
+	return line
+}
+
+// quoteTag quotes tags which contain spaces, quotes or operators of the
+// Timewarrior command line.
+func quoteTag(tag string) string {
+	if tag != "" && !strings.ContainsAny(tag, " \t\"\\#+-/()<^!=~_%") {
+		return tag
+	}
+	return quote(tag)
+}
+
+// quote returns s in double quotes with quotes and backslashes escaped.
+func quote(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", " ").Replace(s)
+	return `"` + s + `"`
+}
@@ -0,0 +1,115 @@
+package timewarrior
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParse(t *testing.T) {
+	at := func(day, hour, min int) time.Time {
+		return time.Date(2026, time.January, day, hour, min, 0, 0, time.UTC).In(time.Now().Location())
+	}
+
+	data, err := ioutil.ReadFile("testdata/2026-01.data")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Interval{
+		{Start: at(5, 8, 0), End: at(5, 12, 0), Tags: []string{"acme", "review"}},
+		{Start: at(5, 13, 0), End: at(5, 16, 30), Tags: []string{"acme", "code review"}, Annotation: `fixing "invoices"`},
+		{Start: at(6, 7, 30), End: at(6, 8, 0), Tags: []string{"project:acme", "standup"}},
+		{Start: at(6, 22, 0), End: at(7, 2, 0), Tags: []string{"night-shift"}, Annotation: `deploy, part 2 \ hotfix`},
+		{Start: at(7, 9, 0), End: at(7, 9, 30)},
+		{Start: at(7, 10, 0), End: at(7, 11, 0), Annotation: "no tags"},
+		{Start: at(31, 15, 0), Tags: []string{"acme"}},
+	}
+
+	got, err := Parse(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Parse() differs: (-want +got)\n%s", diff)
+	}
+
+	// writing the intervals again results in the same file
+	var out strings.Builder
+	if err := Write(&out, got); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if diff := cmp.Diff(string(data), out.String()); diff != "" {
+		t.Errorf("Write() differs: (-want +got)\n%s", diff)
+	}
+}
+
+func TestParseLine(t *testing.T) {
+	start := time.Date(2026, time.January, 5, 8, 0, 0, 0, time.UTC).In(time.Now().Location())
+	end := time.Date(2026, time.January, 5, 12, 0, 0, 0, time.UTC).In(time.Now().Location())
+
+	tests := []struct {
+		name string
+		line string
+		want Interval
+	}{
+		{
+			name: "unquoted annotation",
+			line: "inc 20260105T080000Z - 20260105T120000Z # acme # fixing invoices",
+			want: Interval{Start: start, End: end, Tags: []string{"acme"}, Annotation: "fixing invoices"},
+		},
+		{
+			name: "quoted hash",
+			line: `inc 20260105T080000Z - 20260105T120000Z # "C#" "a \"b\""`,
+			want: Interval{Start: start, End: end, Tags: []string{"C#", `a "b"`}},
+		},
+		{
+			name: "empty tags",
+			line: "inc 20260105T080000Z - 20260105T120000Z #",
+			want: Interval{Start: start, End: end},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(strings.NewReader(tt.line))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if diff := cmp.Diff([]Interval{tt.want}, got); diff != "" {
+				t.Errorf("Parse() differs: (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "unknown line", data: "exc monday <8:00:00 >18:00:00\n"},
+		{name: "invalid time", data: "inc 2026-01-05T08:00:00Z\n"},
+		{name: "missing end", data: "inc 20260105T080000Z -\n"},
+		{name: "end before start", data: "inc 20260105T080000Z - 20260105T070000Z\n"},
+		{name: "unterminated quote", data: "inc 20260105T080000Z # \"acme\n"},
+		{name: "text after annotation", data: "inc 20260105T080000Z # acme # \"note\" more\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(strings.NewReader(tt.data)); err == nil {
+				t.Errorf("Parse() succeeded")
+			}
+		})
+	}
+}
+
+func TestFileName(t *testing.T) {
+	// the month is the one of the UTC time
+	start := time.Date(2026, time.February, 1, 0, 30, 0, 0, time.FixedZone("CET", 3600))
+	if got, want := FileName(start), "2026-01.data"; got != want {
+		t.Errorf("FileName() = %q, want %q", got, want)
+	}
+}